@@ -0,0 +1,88 @@
+/// Folds full-width ASCII and the ideographic space to their half-width forms
+/// and traditional Chinese characters to simplified, then collapses
+/// whitespace. Case and accents are kept, so the result still reads as a
+/// title and can be sent to TMDB as a query. `權力的遊戲` and a full-width
+/// `ＧＯＴ` come out as `权力的游戏` and `GOT`.
+///
+/// The traditional table covers characters common in film and series titles
+/// rather than the whole script; unlisted characters pass through unchanged.
+String normalizeTitle(String raw) {
+  final buffer = StringBuffer();
+  for (final rune in raw.runes) {
+    if (rune == 0x3000) {
+      buffer.writeCharCode(0x20);
+    } else if (rune >= 0xFF01 && rune <= 0xFF5E) {
+      buffer.writeCharCode(rune - 0xFEE0);
+    } else {
+      final char = String.fromCharCode(rune);
+      buffer.write(_simplified[char] ?? char);
+    }
+  }
+  return buffer.toString().replaceAll(RegExp(r'\s+'), ' ').trim();
+}
+
+/// [normalizeTitle] plus lowercasing and Latin diacritic folding
+/// (`Amélie` → `amelie`, `Straße` → `strasse`): the key to compare titles by,
+/// never to display.
+String titleMatchKey(String raw) {
+  final buffer = StringBuffer();
+  for (final rune in normalizeTitle(raw).toLowerCase().runes) {
+    final char = String.fromCharCode(rune);
+    buffer.write(_foldedLatin[char] ?? char);
+  }
+  return buffer.toString();
+}
+
+const _traditionalChars =
+    '權遊戲劍與龍鳳門們個這來時間東車馬鳥魚貓獅夢愛戀島國華風雲電視劇節樂'
+    '書畫語說話記傳紀錄實驗學習師醫長開關頭臉變戰爭軍殺無盡鐘鏡銀鐵錢對當'
+    '從後發現點熱亂歡萬歲歷廣場區機動團圓聖靈獸蟲鬥亞歐漢義俠讓詩豐飛難滅'
+    '嗎裡麼紅綠藍黃體歸號遠進運過還邊達選連週鄉陽陰隊際險隱雙雜雞離響頂項'
+    '順預領題顏願類顯飯飲館驚髮麗黨齊龜親觀計訊設許證評詞試該認誤誰請調談'
+    '論諜謀謎講謝識譯護讀貝負財貨貴買賣費賊資賞賭賽贏趕趙躍輕輪輸轉辦農違'
+    '遺鄭醜鋼錯鍋閃閉閒闖陣陳陸隨雖靜韓頻顧餘騎騙驅鬧鮮鯨鳴鷹麥齡態應懷懸'
+    '擊據擁攝敗敵數斷會條極槍標樣橋殘氣淚測溫滿漁潛澤濟灣災為烏煉燈燒爺獄'
+    '獨獵環產畢異療盜監眾禮禍種穩窮競筆範簡糧約級紙細終組結絕給統絲經綁綜'
+    '線編緣縣總織繼續罰聞聯聲聽職腦腳臨舊艦藝藥蘇蘭處補裝襲見規覺訪詭誕閱'
+    '階養髒壞壓夠奪奮婦媽孫寶將專尋導層屬幣幫廳強彈徵徑憶戶掃掛換揮損搶擔'
+    '擴晉曉暫樓樹橫決沒淺湯準溝瀏煙爾牆狀猶獎瑪瓊癒盤碼確稱穀紋純紗絨緊練'
+    '縮罵羅聰肅脅腫膽興舉艱莊葉蓋薩蝦螢術衛衝複訂託詐誘課諾謊譜豬貍賜質跡'
+    '蹤軟較載輩辭遲郵釋鎖鎮闊隻頸顫颱餅馳駕騰鬆鴻鵝黴齒';
+const _simplifiedChars =
+    '权游戏剑与龙凤门们个这来时间东车马鸟鱼猫狮梦爱恋岛国华风云电视剧节乐'
+    '书画语说话记传纪录实验学习师医长开关头脸变战争军杀无尽钟镜银铁钱对当'
+    '从后发现点热乱欢万岁历广场区机动团圆圣灵兽虫斗亚欧汉义侠让诗丰飞难灭'
+    '吗里么红绿蓝黄体归号远进运过还边达选连周乡阳阴队际险隐双杂鸡离响顶项'
+    '顺预领题颜愿类显饭饮馆惊发丽党齐龟亲观计讯设许证评词试该认误谁请调谈'
+    '论谍谋谜讲谢识译护读贝负财货贵买卖费贼资赏赌赛赢赶赵跃轻轮输转办农违'
+    '遗郑丑钢错锅闪闭闲闯阵陈陆随虽静韩频顾余骑骗驱闹鲜鲸鸣鹰麦龄态应怀悬'
+    '击据拥摄败敌数断会条极枪标样桥残气泪测温满渔潜泽济湾灾为乌炼灯烧爷狱'
+    '独猎环产毕异疗盗监众礼祸种稳穷竞笔范简粮约级纸细终组结绝给统丝经绑综'
+    '线编缘县总织继续罚闻联声听职脑脚临旧舰艺药苏兰处补装袭见规觉访诡诞阅'
+    '阶养脏坏压够夺奋妇妈孙宝将专寻导层属币帮厅强弹征径忆户扫挂换挥损抢担'
+    '扩晋晓暂楼树横决没浅汤准沟浏烟尔墙状犹奖玛琼愈盘码确称谷纹纯纱绒紧练'
+    '缩骂罗聪肃胁肿胆兴举艰庄叶盖萨虾萤术卫冲复订托诈诱课诺谎谱猪狸赐质迹'
+    '踪软较载辈辞迟邮释锁镇阔只颈颤台饼驰驾腾松鸿鹅霉齿';
+
+const _accentedChars =
+    'àáâãäåçèéêëìíîïñòóôõöùúûüýÿāăąćĉċčďēĕėęěĝğġģĥĩīĭįĵķĺļľńņňōŏő'
+    'ŕŗřśŝşšţťũūŭůűųŵŷźżžøđłħı';
+const _plainChars =
+    'aaaaaaceeeeiiiinooooouuuuyyaaaccccdeeeeegggghiiiijklllnnnooo'
+    'rrrssssttuuuuuuwyzzzodlhi';
+
+final Map<String, String> _simplified = _zip(
+  _traditionalChars,
+  _simplifiedChars,
+);
+
+final Map<String, String> _foldedLatin = {
+  ..._zip(_accentedChars, _plainChars),
+  'ß': 'ss',
+  'æ': 'ae',
+  'œ': 'oe',
+};
+
+Map<String, String> _zip(String from, String to) => {
+  for (var i = 0; i < from.length; i++) from[i]: to[i],
+};
@@ -15,6 +15,23 @@ class FilmlyImageCache {
 
   static CacheManager? _instance;
 
+  /// Query parameters added to image URLs per server origin when they are
+  /// loaded, so credentials such as a Plex token never have to be stored with
+  /// the URL in the library database.
+  static final _originQueries = <String, Map<String, String>>{};
+
+  /// Adds [query] to every image URL under [baseUrl]; an empty [query]
+  /// clears it.
+  static void setOriginQuery(String baseUrl, Map<String, String> query) {
+    final origin = _origin(baseUrl);
+    if (origin == null) return;
+    if (query.isEmpty) {
+      _originQueries.remove(origin);
+    } else {
+      _originQueries[origin] = Map.unmodifiable(query);
+    }
+  }
+
   /// Lazily created so pure unit tests never touch path_provider / platform
   /// channels just by importing this library.
   static CacheManager get instance {
@@ -38,7 +55,7 @@ class FilmlyImageCache {
     final value = path?.trim() ?? '';
     if (value.isEmpty) return null;
     if (value.startsWith('http://') || value.startsWith('https://')) {
-      return value;
+      return _withOriginQuery(value);
     }
     if (value.startsWith('/')) {
       return 'https://image.tmdb.org/t/p/${size.pathSegment}$value';
@@ -46,6 +63,24 @@ class FilmlyImageCache {
     return null;
   }
 
+  static String _withOriginQuery(String url) {
+    if (_originQueries.isEmpty) return url;
+    final query = _originQueries[_origin(url)];
+    if (query == null) return url;
+    final uri = Uri.parse(url);
+    return uri
+        .replace(queryParameters: {...uri.queryParameters, ...query})
+        .toString();
+  }
+
+  static String? _origin(String url) {
+    final uri = Uri.tryParse(url);
+    if (uri == null || (uri.scheme != 'http' && uri.scheme != 'https')) {
+      return null;
+    }
+    return uri.origin;
+  }
+
   /// True when Flutter platform channels are available (not plain unit tests).
   static bool get _diskCacheAvailable {
     try {
@@ -68,7 +103,7 @@ class FilmlyImageCache {
       if (url == null || url.isEmpty) continue;
       if (!url.startsWith('http://') && !url.startsWith('https://')) continue;
       try {
-        await instance.downloadFile(url);
+        await instance.downloadFile(_withOriginQuery(url));
       } catch (_) {
         // Network / TMDB blips are fine — UI still falls back to placeholder.
       }
@@ -0,0 +1,64 @@
+import 'dart:io';
+import 'dart:ui';
+
+import 'package:flutter/foundation.dart';
+import 'package:path/path.dart' as p;
+import 'package:path_provider/path_provider.dart';
+
+/// Records uncaught errors with their stack traces in `crash.log` under the
+/// app support directory and marks them handled, so one bad file in a scan
+/// or a failed proxy read is logged instead of tearing down the window.
+abstract final class CrashLog {
+  /// The log starts over once it grows past this, so it never needs cleanup.
+  static const maxBytes = 1024 * 1024;
+
+  static Future<File?>? _file;
+
+  /// Hooks [FlutterError.onError] and [PlatformDispatcher.onError]. Call once
+  /// per engine, after the binding is initialized.
+  static void install() {
+    _file ??= _open();
+    final previous = FlutterError.onError;
+    FlutterError.onError = (details) {
+      record(details.exception, details.stack, 'flutter');
+      previous?.call(details);
+    };
+    PlatformDispatcher.instance.onError = (error, stack) {
+      record(error, stack, 'async');
+      return true;
+    };
+  }
+
+  static void record(Object error, StackTrace? stack, String origin) {
+    debugPrint('[$origin] $error');
+    _file?.then((file) async {
+      if (file == null) return;
+      final entry = StringBuffer()
+        ..writeln('=== ${DateTime.now().toIso8601String()} [$origin]')
+        ..writeln(error)
+        ..writeln(stack ?? StackTrace.empty);
+      try {
+        await file.writeAsString(
+          entry.toString(),
+          mode: FileMode.append,
+          flush: true,
+        );
+      } catch (_) {
+        // Nowhere left to report a failure to log; drop it.
+      }
+    });
+  }
+
+  static Future<File?> _open() async {
+    try {
+      final support = await getApplicationSupportDirectory();
+      final file = File(p.join(support.path, 'crash.log'));
+      if (await file.exists() && await file.length() > maxBytes) {
+        await file.delete();
+      }
+      return file;
+    } catch (_) {
+      return null;
+    }
+  }
+}
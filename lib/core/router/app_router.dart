@@ -7,7 +7,9 @@ import '../../data/models/library_shelf.dart';
 import '../../data/models/media.dart';
 import '../../data/models/resource_source.dart';
 import '../../features/config/config_page.dart';
+import '../../features/config/dlna_browser_page.dart';
 import '../../features/config/emby_browser_page.dart';
+import '../../features/config/plex_browser_page.dart';
 import '../../features/config/smb_browser_page.dart';
 import '../../features/config/webdav_browser_page.dart';
 import '../../features/home/home_page.dart';
@@ -222,6 +224,18 @@ GoRouter createAppRouter({String initialLocation = '/'}) => GoRouter(
             EmbyBrowserPage(sourceId: state.uri.queryParameters['sourceId']),
           ),
         ),
+        GoRoute(
+          path: '/dlna',
+          pageBuilder: (context, state) =>
+              _fadePage(state, const DlnaBrowserPage()),
+        ),
+        GoRoute(
+          path: '/plex',
+          pageBuilder: (context, state) => _fadePage(
+            state,
+            PlexBrowserPage(sourceId: state.uri.queryParameters['sourceId']),
+          ),
+        ),
         GoRoute(
           path: '/config',
           pageBuilder: (context, state) => _fadePage(state, const ConfigPage()),
@@ -0,0 +1,78 @@
+/// A fansub-style file name such as `[SubsPlease] Frieren - 01v2 (1080p)` or
+/// `[Nekomoe kissaten][Frieren][01][1080p]`.
+///
+/// These releases number episodes absolutely within a series (or season
+/// cour) and never carry an `SxxExx` tag, so the regular TV heuristics file
+/// them as movies or under the release group's folder name.
+class AnimeReleaseName {
+  const AnimeReleaseName({
+    required this.group,
+    required this.title,
+    required this.episode,
+    this.season,
+    this.version = 1,
+  });
+
+  final String group;
+
+  /// Series title with any season marker (`S2`, `2nd Season`) removed.
+  final String title;
+
+  /// Episode number as written, usually absolute within the series.
+  final int episode;
+
+  /// Season from a marker in the title, or null when the name has none.
+  final int? season;
+
+  /// Release revision; `v2` fixes a `v1` of the same episode.
+  final int version;
+
+  /// `[Group] Title - 01v2 ...`
+  static final _dashPattern = RegExp(
+    r'^\s*\[([^\]]+)\]\s*(.+?)\s+-\s+(\d{1,4})(?:v(\d))?(?=[\s\[(.]|$)',
+    caseSensitive: false,
+  );
+
+  /// `[Group][Title][01v2]...` or `[Group] Title [01]...`
+  static final _bracketPattern = RegExp(
+    r'^\s*\[([^\]]+)\]\s*\[?([^\[\]]+?)\]?\s*\[(\d{1,4})(?:v(\d))?\]',
+    caseSensitive: false,
+  );
+
+  static final _seasonPattern = RegExp(
+    r'\s+(?:s(\d{1,2})|season\s*(\d{1,2})|(\d{1,2})(?:st|nd|rd|th)\s+season)$',
+    caseSensitive: false,
+  );
+
+  /// Parses [basename] (without extension); null when it isn't fansub-style.
+  static AnimeReleaseName? tryParse(String basename) {
+    final match =
+        _dashPattern.firstMatch(basename) ??
+        _bracketPattern.firstMatch(basename);
+    if (match == null) return null;
+
+    final digits = match.group(3)!;
+    final episode = int.parse(digits);
+    // `[Group] Movie [2019]` is a year, not episode 2019.
+    if (digits.length == 4 && episode >= 1900 && episode <= 2099) return null;
+
+    var title = match.group(2)!.trim();
+    int? season;
+    final seasonMatch = _seasonPattern.firstMatch(title);
+    if (seasonMatch != null) {
+      season = int.parse(
+        seasonMatch.group(1) ?? seasonMatch.group(2) ?? seasonMatch.group(3)!,
+      );
+      title = title.substring(0, seasonMatch.start).trim();
+    }
+    if (title.isEmpty) return null;
+
+    return AnimeReleaseName(
+      group: match.group(1)!.trim(),
+      title: title,
+      episode: episode,
+      season: season,
+      version: int.tryParse(match.group(4) ?? '') ?? 1,
+    );
+  }
+}
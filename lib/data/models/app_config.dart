@@ -25,12 +25,17 @@ class AppConfig {
     this.aiRemoteEndpoint = '',
     this.aiMemoryEnabled = true,
     this.autoScanOnStartup = true,
+    this.autoScanIntervalHours = 0,
+    this.extrasPatterns = const [],
+    this.excludedDirectoryNames = const [],
     this.webdavUrl = '',
     this.webdavUsername = '',
     this.webdavPassword = '',
     this.embyUrl = '',
     this.embyUsername = '',
     this.embyPassword = '',
+    this.plexUrl = '',
+    this.plexToken = '',
     this.resourceSources = const [],
   });
 
@@ -53,12 +58,26 @@ class AppConfig {
   final String aiRemoteEndpoint;
   final bool aiMemoryEnabled;
   final bool autoScanOnStartup;
+
+  /// Re-runs the incremental auto-scan this often while the app stays open;
+  /// 0 disables the periodic rescan.
+  final int autoScanIntervalHours;
+
+  /// Extra regexes (matched against the file path) for bonus clips the
+  /// built-in sample/trailer rules miss; matching files are not imported.
+  final List<String> extrasPatterns;
+
+  /// Folder names scans skip, replacing the built-in NAS/OS housekeeping list
+  /// (`@eaDir`, `#recycle`, ...); empty keeps that list.
+  final List<String> excludedDirectoryNames;
   final String webdavUrl;
   final String webdavUsername;
   final String webdavPassword;
   final String embyUrl;
   final String embyUsername;
   final String embyPassword;
+  final String plexUrl;
+  final String plexToken;
   final List<ResourceSource> resourceSources;
 
   factory AppConfig.fromJson(Map<String, dynamic> json) {
@@ -72,6 +91,9 @@ class AppConfig {
 
     final folders = json['selectedFolders'];
     final autoScan = json['autoScanOnStartup'];
+    final scanInterval = json['autoScanIntervalHours'];
+    final extras = json['extrasPatterns'];
+    final excludedDirs = json['excludedDirectoryNames'];
     final rawSources = json['resourceSources'];
     final sources = rawSources is List
         ? rawSources
@@ -146,12 +168,23 @@ class AppConfig {
           ? json['aiMemoryEnabled'] as bool
           : true,
       autoScanOnStartup: autoScan is bool ? autoScan : true,
+      autoScanIntervalHours: scanInterval is int && scanInterval > 0
+          ? scanInterval
+          : 0,
+      extrasPatterns: extras is List
+          ? extras.map((e) => e.toString()).toList(growable: false)
+          : const [],
+      excludedDirectoryNames: excludedDirs is List
+          ? excludedDirs.map((e) => e.toString()).toList(growable: false)
+          : const [],
       webdavUrl: pick(['webdavUrl', 'webdavHost']),
       webdavUsername: pick(['webdavUsername']),
       webdavPassword: pick(['webdavPassword']),
       embyUrl: pick(['embyUrl', 'embyHost']),
       embyUsername: pick(['embyUsername']),
       embyPassword: pick(['embyPassword']),
+      plexUrl: pick(['plexUrl']),
+      plexToken: pick(['plexToken']),
       resourceSources: migratedSources,
     );
   }
@@ -176,12 +209,17 @@ class AppConfig {
     'aiRemoteEndpoint': aiRemoteEndpoint,
     'aiMemoryEnabled': aiMemoryEnabled,
     'autoScanOnStartup': autoScanOnStartup,
+    'autoScanIntervalHours': autoScanIntervalHours,
+    'extrasPatterns': extrasPatterns,
+    'excludedDirectoryNames': excludedDirectoryNames,
     'webdavUrl': webdavUrl,
     'webdavUsername': webdavUsername,
     'webdavPassword': webdavPassword,
     'embyUrl': embyUrl,
     'embyUsername': embyUsername,
     'embyPassword': embyPassword,
+    'plexUrl': plexUrl,
+    'plexToken': plexToken,
     'resourceSources': resourceSources
         .map((source) => source.toJson())
         .toList(),
@@ -207,12 +245,17 @@ class AppConfig {
     String? aiRemoteEndpoint,
     bool? aiMemoryEnabled,
     bool? autoScanOnStartup,
+    int? autoScanIntervalHours,
+    List<String>? extrasPatterns,
+    List<String>? excludedDirectoryNames,
     String? webdavUrl,
     String? webdavUsername,
     String? webdavPassword,
     String? embyUrl,
     String? embyUsername,
     String? embyPassword,
+    String? plexUrl,
+    String? plexToken,
     List<ResourceSource>? resourceSources,
   }) {
     return AppConfig(
@@ -235,12 +278,19 @@ class AppConfig {
       aiRemoteEndpoint: aiRemoteEndpoint ?? this.aiRemoteEndpoint,
       aiMemoryEnabled: aiMemoryEnabled ?? this.aiMemoryEnabled,
       autoScanOnStartup: autoScanOnStartup ?? this.autoScanOnStartup,
+      autoScanIntervalHours:
+          autoScanIntervalHours ?? this.autoScanIntervalHours,
+      extrasPatterns: extrasPatterns ?? this.extrasPatterns,
+      excludedDirectoryNames:
+          excludedDirectoryNames ?? this.excludedDirectoryNames,
       webdavUrl: webdavUrl ?? this.webdavUrl,
       webdavUsername: webdavUsername ?? this.webdavUsername,
       webdavPassword: webdavPassword ?? this.webdavPassword,
       embyUrl: embyUrl ?? this.embyUrl,
       embyUsername: embyUsername ?? this.embyUsername,
       embyPassword: embyPassword ?? this.embyPassword,
+      plexUrl: plexUrl ?? this.plexUrl,
+      plexToken: plexToken ?? this.plexToken,
       resourceSources: resourceSources ?? this.resourceSources,
     );
   }
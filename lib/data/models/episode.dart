@@ -58,4 +58,18 @@ class Season {
   final List<Episode> episodes;
 
   String get label => 'Season $number';
+
+  /// Episode numbers absent from this season: every hole between 1 and the
+  /// highest episode on disk, extended up to [expectedCount] (e.g. TMDB's
+  /// aired count) when known. Specials (season 0) have no fixed numbering and
+  /// never report gaps.
+  List<int> missingEpisodes([int? expectedCount]) {
+    if (number == 0) return const [];
+    final present = {for (final episode in episodes) episode.episodeNumber};
+    var last = expectedCount ?? 0;
+    for (final n in present) {
+      if (n > last) last = n;
+    }
+    return [for (var n = 1; n <= last; n++) if (!present.contains(n)) n];
+  }
 }
@@ -1,5 +1,7 @@
 import 'dart:convert';
 
+import '../../core/formatters/title_normalizer.dart';
+
 enum MediaType {
   movie,
   tv,
@@ -63,6 +65,10 @@ class Media {
   final String lastUpdated;
   final bool isFavorite;
 
+  /// [title] with full-width characters and traditional Chinese folded (see
+  /// [normalizeTitle]); used for matching, while [title] stays as stored.
+  String get normalizedTitle => normalizeTitle(title);
+
   /// TMDB id parsed from [detailsJson], used to fetch episode-level metadata.
   /// Null when the item hasn't been enriched or predates id storage.
   Object? get tmdbId {
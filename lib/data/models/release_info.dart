@@ -0,0 +1,158 @@
+import 'package:path/path.dart' as path;
+
+/// Quality facts read from a release file name such as
+/// `Dune.2021.2160p.UHD.BluRay.REMUX.HDR10.HEVC.TrueHD.7.1-FGT.mkv`, so
+/// duplicates can be ranked and badges shown without probing the file.
+class ReleaseInfo {
+  const ReleaseInfo({
+    this.resolution,
+    this.source,
+    this.codec,
+    this.hdr = const [],
+    this.releaseGroup,
+  });
+
+  /// Vertical resolution: 2160, 1080, 720, 576, or 480.
+  final int? resolution;
+
+  /// `Remux`, `BluRay`, `WEB-DL`, `WEBRip`, `HDTV`, or `DVD`.
+  final String? source;
+
+  /// `AV1`, `HEVC`, `AVC`, or `XviD`.
+  final String? codec;
+
+  /// `DV`, `HDR10+`, `HDR10`, `HDR`, or `HLG`, strongest first.
+  final List<String> hdr;
+  final String? releaseGroup;
+
+  static final _resolutions = <RegExp, int>{
+    RegExp(r'\b(?:2160p|4k|uhd)\b', caseSensitive: false): 2160,
+    RegExp(r'\b1080[pi]\b', caseSensitive: false): 1080,
+    RegExp(r'\b720p\b', caseSensitive: false): 720,
+    RegExp(r'\b576[pi]\b', caseSensitive: false): 576,
+    RegExp(r'\b480[pi]\b', caseSensitive: false): 480,
+  };
+
+  // Ordered so a `BluRay.REMUX` name reports Remux, not BluRay.
+  static final _sources = <RegExp, String>{
+    RegExp(r'\bremux\b', caseSensitive: false): 'Remux',
+    RegExp(r'\b(?:blu-?ray|bdrip|brrip|bd)\b', caseSensitive: false): 'BluRay',
+    RegExp(r'\bweb-?dl\b', caseSensitive: false): 'WEB-DL',
+    RegExp(r'\bweb-?rip\b', caseSensitive: false): 'WEBRip',
+    RegExp(r'\bhdtv\b', caseSensitive: false): 'HDTV',
+    RegExp(r'\bdvd(?:rip|5|9)?\b', caseSensitive: false): 'DVD',
+  };
+
+  static final _codecs = <RegExp, String>{
+    RegExp(r'\bav1\b', caseSensitive: false): 'AV1',
+    RegExp(r'\b(?:x265|h ?265|hevc)\b', caseSensitive: false): 'HEVC',
+    RegExp(r'\b(?:x264|h ?264|avc)\b', caseSensitive: false): 'AVC',
+    RegExp(r'\bxvid\b', caseSensitive: false): 'XviD',
+  };
+
+  static final _hdrFlags = <RegExp, String>{
+    RegExp(r'\b(?:dv|dovi|dolby ?vision)\b', caseSensitive: false): 'DV',
+    RegExp(r'\bhdr10(?:\+|plus)', caseSensitive: false): 'HDR10+',
+    RegExp(r'\bhdr10\b(?!\+)', caseSensitive: false): 'HDR10',
+    RegExp(r'\bhdr\b', caseSensitive: false): 'HDR',
+    RegExp(r'\bhlg\b', caseSensitive: false): 'HLG',
+  };
+
+  /// A trailing `-GROUP` tag, optionally followed by a bracketed site tag.
+  /// Only trusted when the name also carries a resolution, source or codec,
+  /// so a hyphenated title like `Spider-Man` isn't read as group `Man`.
+  static final _groupPattern = RegExp(r'-([A-Za-z0-9@]+)(?:\[[^\]]*\])?$');
+
+  /// Parses the file name of [filePath]; directories are ignored because
+  /// season packs often carry a different quality than their files.
+  factory ReleaseInfo.parse(String filePath) {
+    final name = path.posix.basenameWithoutExtension(
+      filePath.replaceAll('\\', '/'),
+    );
+    // Treat dots and underscores as word separators for the \b patterns.
+    final words = name.replaceAll(RegExp(r'[._]'), ' ');
+
+    String? first(Map<RegExp, String> table) {
+      for (final entry in table.entries) {
+        if (entry.key.hasMatch(words)) return entry.value;
+      }
+      return null;
+    }
+
+    int? resolution;
+    for (final entry in _resolutions.entries) {
+      if (entry.key.hasMatch(words)) {
+        resolution = entry.value;
+        break;
+      }
+    }
+
+    final hdr = [
+      for (final entry in _hdrFlags.entries)
+        if (entry.key.hasMatch(words)) entry.value,
+    ];
+    // `HDR10` implies `HDR`; keep only the most specific tag of that family.
+    if (hdr.contains('HDR') && hdr.any((tag) => tag.startsWith('HDR10'))) {
+      hdr.remove('HDR');
+    }
+
+    final source = first(_sources);
+    final codec = first(_codecs);
+    final hasReleaseTags =
+        resolution != null || source != null || codec != null;
+    final group = hasReleaseTags
+        ? _groupPattern.firstMatch(name)?.group(1)
+        : null;
+    return ReleaseInfo(
+      resolution: resolution,
+      source: source,
+      codec: codec,
+      hdr: List.unmodifiable(hdr),
+      releaseGroup: group == null || _isTechnicalTag(group) ? null : group,
+    );
+  }
+
+  bool get isEmpty =>
+      resolution == null &&
+      source == null &&
+      codec == null &&
+      hdr.isEmpty &&
+      releaseGroup == null;
+
+  /// Short labels for display, e.g. `['4K', 'Remux', 'HEVC', 'DV']`.
+  List<String> get badges => [
+    if (resolution != null) resolution == 2160 ? '4K' : '${resolution}p',
+    ?source,
+    ?codec,
+    ...hdr,
+  ];
+
+  /// Ranks copies of the same title: resolution first, then source, HDR and
+  /// codec. Only meaningful relative to another [ReleaseInfo].
+  int get score {
+    var score = (resolution ?? 0) * 10;
+    score += switch (source) {
+      'Remux' => 500,
+      'BluRay' => 400,
+      'WEB-DL' => 300,
+      'WEBRip' => 200,
+      'HDTV' => 100,
+      'DVD' => 50,
+      _ => 0,
+    };
+    if (hdr.isNotEmpty) score += 40;
+    score += switch (codec) {
+      'AV1' || 'HEVC' => 20,
+      'AVC' => 10,
+      _ => 0,
+    };
+    return score;
+  }
+
+  /// Suffixes like `-DL` or `-HD` that are part of a source/audio tag rather
+  /// than a group name.
+  static bool _isTechnicalTag(String group) {
+    final lower = group.toLowerCase();
+    return RegExp(r'^(?:dl|hd|ma|rip|x26[45]|\d+p?)$').hasMatch(lower);
+  }
+}
@@ -5,7 +5,7 @@ import 'package:flutter/material.dart';
 /// The source model is intentionally transport-oriented rather than tied to a
 /// single browser page. This lets the same source appear on iOS and desktop,
 /// while each platform can choose its own browsing and import affordances.
-enum ResourceSourceType { local, webdav, smb, emby, jellyfin, cloud }
+enum ResourceSourceType { local, webdav, smb, emby, jellyfin, plex, cloud }
 
 extension ResourceSourceTypePresentation on ResourceSourceType {
   String get label => switch (this) {
@@ -14,6 +14,7 @@ extension ResourceSourceTypePresentation on ResourceSourceType {
     ResourceSourceType.smb => 'SMB',
     ResourceSourceType.emby => 'Emby',
     ResourceSourceType.jellyfin => 'Jellyfin',
+    ResourceSourceType.plex => 'Plex',
     ResourceSourceType.cloud => '云盘',
   };
 
@@ -23,6 +24,7 @@ extension ResourceSourceTypePresentation on ResourceSourceType {
     ResourceSourceType.smb => Icons.storage_rounded,
     ResourceSourceType.emby => Icons.ondemand_video_rounded,
     ResourceSourceType.jellyfin => Icons.live_tv_rounded,
+    ResourceSourceType.plex => Icons.smart_display_rounded,
     ResourceSourceType.cloud => Icons.cloud_rounded,
   };
 
@@ -2,6 +2,7 @@ import 'package:drift/drift.dart';
 import 'package:path/path.dart' as p;
 
 import '../database/database.dart';
+import '../models/anime_release_name.dart';
 import '../models/episode.dart';
 
 /// Data access for episodes associated with TV shows.
@@ -204,6 +205,9 @@ class EpisodeRepository {
     }
     if (path.startsWith('smb://') || path.startsWith('/')) score += 5;
     if (full.isNotEmpty) score += 2;
+    // A fansub `v2` re-release supersedes `v1` of the same episode.
+    final anime = AnimeReleaseName.tryParse(p.basenameWithoutExtension(source));
+    if (anime != null) score += anime.version - 1;
     return score;
   }
 }
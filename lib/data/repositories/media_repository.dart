@@ -2,10 +2,12 @@ import 'dart:convert';
 
 import 'package:drift/drift.dart';
 
+import '../../core/formatters/title_normalizer.dart';
 import '../database/database.dart';
 import '../models/library_shelf.dart';
 import '../models/media.dart';
 import '../models/media_library_query.dart';
+import '../models/release_info.dart';
 
 /// Data access for the media library. Mirrors the query surface of the Electron
 /// MediaDatabase (getByType / getById / upsert / updatePoster / counts).
@@ -55,7 +57,7 @@ class MediaRepository {
       // already filtered in SQL; keep for clarity if both null paths change
     }
 
-    final normalized = searchTerm.trim().toLowerCase();
+    final normalized = titleMatchKey(searchTerm);
     final searchFiltered = normalized.isEmpty
         ? items
         : items.where((media) => _matchesSearch(media, normalized)).toList();
@@ -105,7 +107,7 @@ class MediaRepository {
     var items = await browse(type: type, deduplicateShows: false);
 
     if (searchTerm != null && searchTerm.trim().isNotEmpty) {
-      final norm = searchTerm.trim().toLowerCase();
+      final norm = titleMatchKey(searchTerm);
       items = items.where((m) => _matchesSearch(m, norm)).toList();
     }
 
@@ -288,6 +290,48 @@ class MediaRepository {
     return removed;
   }
 
+  /// Groups movies that are the same film stored more than once, typically
+  /// copies on different shares or servers. Matches on TMDB id, falling back to
+  /// normalized title + year for unmatched rows. Within each group the first
+  /// item is the suggested keeper (best metadata, then the better release by
+  /// file name, e.g. a 4K remux over a 1080p WEB-DL); the rest are removal
+  /// candidates. Nothing is deleted here.
+  Future<List<List<Media>>> findDuplicateMovies() async {
+    final movies = await getByType(MediaType.movie);
+    final groups = <String, List<Media>>{};
+    for (final movie in movies) {
+      final tmdbId = movie.tmdbId;
+      final title = _normalizedShowTitle(movie.title);
+      final key = tmdbId != null
+          ? 'tmdb|$tmdbId'
+          : title.isEmpty
+          ? null
+          : 'title|$title|${movie.year}';
+      if (key == null) continue;
+      groups.putIfAbsent(key, () => []).add(movie);
+    }
+    return groups.values
+        .where((group) => group.length > 1)
+        .map(
+          (group) => group
+            ..sort((a, b) {
+              final quality = _metadataQuality(
+                b,
+              ).compareTo(_metadataQuality(a));
+              if (quality != 0) return quality;
+              final release = _releaseScore(b).compareTo(_releaseScore(a));
+              if (release != 0) return release;
+              return a.id.compareTo(b.id);
+            }),
+        )
+        .toList(growable: false);
+  }
+
+  static int _releaseScore(Media media) {
+    final fullPath = media.fullPath ?? '';
+    return ReleaseInfo.parse(fullPath.isNotEmpty ? fullPath : media.path).score;
+  }
+
   bool _isWeakShowTitle(String title) {
     final t = title.trim();
     if (t.isEmpty) return true;
@@ -483,9 +527,9 @@ class MediaRepository {
       media.genres.join(' '),
       media.path,
       media.fullPath ?? '',
-    ].join(' ').toLowerCase();
+    ].join(' ');
 
-    return tokens.every(haystack.contains);
+    return tokens.every(titleMatchKey(haystack).contains);
   }
 
   bool _matchesGenreTerms(Media media, List<String> normalizedTerms) {
@@ -535,7 +579,7 @@ class MediaRepository {
   /// Normalizes a show title for consolidation. Trailing season tokens are
   /// stripped so "Game Of Thrones S01" and "Game Of Thrones S05" merge.
   String _normalizedShowTitle(String title) {
-    var t = title.trim().toLowerCase();
+    var t = titleMatchKey(title);
     t = t.replaceAll(
       RegExp(
         r'[\s._\-]*(?:s\d{1,2}|season\s*\d+|第[一二三四五六七八九十百\d]+季)\s*$',
@@ -562,6 +606,8 @@ class MediaRepository {
           'webdav|${source['base']?.toString().trim().toLowerCase() ?? ''}',
         'emby' =>
           'emby|${source['base']?.toString().trim().toLowerCase() ?? ''}',
+        'plex' =>
+          'plex|${source['base']?.toString().trim().toLowerCase() ?? ''}',
         _ => kind,
       };
     } catch (_) {
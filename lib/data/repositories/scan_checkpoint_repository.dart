@@ -0,0 +1,86 @@
+import 'dart:convert';
+
+import 'package:drift/drift.dart';
+
+import '../database/database.dart';
+
+/// What an unfinished scan had done when it was last saved.
+class ScanCheckpoint {
+  const ScanCheckpoint({this.completed = const {}, this.mediaIds = const []});
+
+  static const empty = ScanCheckpoint();
+
+  /// Folder paths completed, including all their subfolders.
+  final Set<String> completed;
+
+  /// Media imported before the interruption, so the resumed scan can still
+  /// consolidate and enrich them.
+  final List<String> mediaIds;
+
+  bool get isEmpty => completed.isEmpty;
+}
+
+/// Persists the folders an interrupted library scan already finished, in the
+/// shared config key-value store, so the next scan of the same root can skip
+/// them instead of starting over.
+class ScanCheckpointRepository {
+  ScanCheckpointRepository(this._db);
+
+  static const _keyPrefix = 'scan_checkpoint:';
+
+  /// Checkpoints older than this are dropped on [load]: the folders have
+  /// likely changed since, so a fresh scan is the safer bet.
+  static const maxAge = Duration(days: 7);
+
+  final AppDatabase _db;
+
+  /// The progress of an earlier, unfinished scan of [scanId]; empty when
+  /// there is nothing to resume or the checkpoint is older than [maxAge].
+  Future<ScanCheckpoint> load(String scanId) async {
+    final row = await (_db.select(
+      _db.configEntries,
+    )..where((t) => t.key.equals(_keyFor(scanId)))).getSingleOrNull();
+    if (row == null) return ScanCheckpoint.empty;
+    try {
+      final decoded = jsonDecode(row.value);
+      if (decoded is! Map<String, dynamic>) return ScanCheckpoint.empty;
+      final updatedAt = DateTime.tryParse('${decoded['updatedAt']}');
+      if (updatedAt == null ||
+          DateTime.now().difference(updatedAt) > maxAge) {
+        await clear(scanId);
+        return ScanCheckpoint.empty;
+      }
+      List<String> strings(Object? value) =>
+          value is List ? value.map((e) => e.toString()).toList() : const [];
+      return ScanCheckpoint(
+        completed: strings(decoded['completed']).toSet(),
+        mediaIds: strings(decoded['mediaIds']),
+      );
+    } catch (_) {
+      return ScanCheckpoint.empty;
+    }
+  }
+
+  Future<void> save(String scanId, ScanCheckpoint checkpoint) async {
+    await _db
+        .into(_db.configEntries)
+        .insertOnConflictUpdate(
+          ConfigEntriesCompanion.insert(
+            key: _keyFor(scanId),
+            value: jsonEncode({
+              'completed': checkpoint.completed.toList(growable: false),
+              'mediaIds': checkpoint.mediaIds,
+              'updatedAt': DateTime.now().toIso8601String(),
+            }),
+          ),
+        );
+  }
+
+  Future<void> clear(String scanId) async {
+    await (_db.delete(
+      _db.configEntries,
+    )..where((t) => t.key.equals(_keyFor(scanId)))).go();
+  }
+
+  String _keyFor(String scanId) => '$_keyPrefix$scanId';
+}
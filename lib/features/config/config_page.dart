@@ -9,10 +9,15 @@ import 'package:path_provider/path_provider.dart';
 
 import '../../core/image/filmly_image_cache.dart';
 import '../../data/models/app_config.dart';
+import '../../data/models/media.dart';
 import '../../providers/data_providers.dart';
 import '../../services/data/database_transfer_service.dart';
+import '../../services/library/library_export_service.dart';
+import '../../services/library/library_stats_service.dart';
+import '../../services/library/library_validation_service.dart';
 import '../../data/intelligence/intelligence_models.dart';
 import '../../providers/intelligence_providers.dart';
+import '../../providers/smb_providers.dart';
 import '../../widgets/filmly_design.dart';
 
 /// Apple-styled settings page grouped into: library scan, metadata APIs,
@@ -25,6 +30,8 @@ class ConfigPage extends ConsumerStatefulWidget {
 }
 
 class _ConfigPageState extends ConsumerState<ConfigPage> {
+  static const _periodicScanHours = 6;
+
   final _host = TextEditingController();
   final _username = TextEditingController();
   final _password = TextEditingController();
@@ -47,6 +54,7 @@ class _ConfigPageState extends ConsumerState<ConfigPage> {
   bool _transferring = false;
   bool _aiWorking = false;
   bool _autoScan = true;
+  bool _periodicScan = false;
   bool _aiAllowRemoteText = false;
   bool _aiMemoryEnabled = true;
 
@@ -90,6 +98,7 @@ class _ConfigPageState extends ConsumerState<ConfigPage> {
     _aiAllowRemoteText = c.aiAllowRemoteText;
     _aiMemoryEnabled = c.aiMemoryEnabled;
     _autoScan = c.autoScanOnStartup;
+    _periodicScan = c.autoScanIntervalHours > 0;
     _filled = true;
   }
 
@@ -120,6 +129,7 @@ class _ConfigPageState extends ConsumerState<ConfigPage> {
       aiAllowRemoteText: _aiAllowRemoteText,
       aiMemoryEnabled: _aiMemoryEnabled,
       autoScanOnStartup: _autoScan,
+      autoScanIntervalHours: _periodicScan ? _periodicScanHours : 0,
     );
   }
 
@@ -324,6 +334,245 @@ class _ConfigPageState extends ConsumerState<ConfigPage> {
     }
   }
 
+  Future<void> _exportPlaylist() async {
+    if (_transferring) return;
+    final location = await getSaveLocation(
+      acceptedTypeGroups: const [
+        XTypeGroup(label: 'M3U 播放列表', extensions: ['m3u']),
+        XTypeGroup(label: 'CSV 表格', extensions: ['csv']),
+      ],
+      suggestedName: 'open_filmly-library.m3u',
+      confirmButtonText: '导出',
+    );
+    final path = location?.path;
+    if (path == null || path.isEmpty || !mounted) return;
+
+    setState(() => _transferring = true);
+    try {
+      final file = await LibraryExportService(
+        ref.read(mediaRepositoryProvider),
+        ref.read(episodeRepositoryProvider),
+      ).exportToPath(path);
+      _showSnack('已导出片单：${p.basename(file.path)}');
+    } catch (e) {
+      _showSnack('导出失败：$e');
+    } finally {
+      if (mounted) setState(() => _transferring = false);
+    }
+  }
+
+  Future<void> _showLibraryStats() async {
+    if (_transferring) return;
+    setState(() => _transferring = true);
+    LibraryStats stats;
+    try {
+      stats = await LibraryStatsService(
+        ref.read(mediaRepositoryProvider),
+        ref.read(episodeRepositoryProvider),
+      ).collect();
+    } catch (e) {
+      _showSnack('统计失败：$e');
+      return;
+    } finally {
+      if (mounted) setState(() => _transferring = false);
+    }
+    if (!mounted) return;
+
+    String summarize(Map<String, int> counts) => counts.isEmpty
+        ? '无'
+        : counts.entries.map((e) => '${e.key} ${e.value}').join(' · ');
+    final years = stats.byYear.entries
+        .take(10)
+        .map((e) => '${e.key} ${e.value}');
+    final unmatched = stats.unmatched.take(20).map((media) => media.title);
+    await showDialog<void>(
+      context: context,
+      builder: (context) => AlertDialog(
+        title: const Text('媒体库统计'),
+        content: SingleChildScrollView(
+          child: Text(
+            [
+              '电影 ${stats.movieCount} 部，剧集 ${stats.showCount} 部'
+                  '（共 ${stats.episodeCount} 集）',
+              '分辨率（按文件）：${summarize(stats.byResolution)}',
+              '编码（按文件）：${summarize(stats.byCodec)}',
+              '年份：${years.isEmpty ? '无' : years.join(' · ')}',
+              '未匹配：${stats.unmatched.length} 项'
+                  '${unmatched.isEmpty ? '' : '\n${unmatched.join('\n')}'}',
+            ].join('\n\n'),
+          ),
+        ),
+        actions: [
+          TextButton(
+            onPressed: () => Navigator.of(context).pop(),
+            child: const Text('关闭'),
+          ),
+        ],
+      ),
+    );
+  }
+
+  Future<void> _validateLibrary() async {
+    if (_transferring) return;
+    setState(() => _transferring = true);
+    LibraryValidationService service;
+    LibraryValidationReport report;
+    try {
+      final config = await ref.read(configProvider.future);
+      service = LibraryValidationService(
+        ref.read(mediaRepositoryProvider),
+        ref.read(episodeRepositoryProvider),
+        ref.read(smbServiceProvider),
+        config.selectedFolders,
+      );
+      report = await service.validate();
+    } catch (e) {
+      _showSnack('检查失败：$e');
+      return;
+    } finally {
+      if (mounted) setState(() => _transferring = false);
+    }
+    if (!mounted) return;
+    final unreachable = report.unreachableRoots.isEmpty
+        ? ''
+        : '，${report.unreachableRoots.length} 个来源无法访问：'
+              '${report.unreachableRoots.join('、')}';
+    if (report.issues.isEmpty) {
+      _showSnack(
+        '检查完成：${report.checked} 个文件均正常'
+        '${report.skipped > 0 ? '，${report.skipped} 个文件未检查' : ''}'
+        '$unreachable',
+      );
+      return;
+    }
+
+    final missing = report.issues
+        .where((issue) => issue.code == LibraryIssueCode.missing)
+        .length;
+    final remove = await showDialog<bool>(
+      context: context,
+      builder: (context) => AlertDialog(
+        title: Text('发现 ${report.issues.length} 个问题'),
+        content: SingleChildScrollView(
+          child: Text(
+            [
+              if (report.unreachableRoots.isNotEmpty)
+                '以下来源无法访问，其中的文件未检查：\n'
+                    '${report.unreachableRoots.join('\n')}\n',
+              ...report.issues
+                  .take(50)
+                  .map((issue) => '[${issue.code.label}] ${issue.path}'),
+            ].join('\n'),
+          ),
+        ),
+        actions: [
+          TextButton(
+            onPressed: () => Navigator.of(context).pop(false),
+            child: const Text('关闭'),
+          ),
+          if (missing > 0)
+            FilledButton(
+              onPressed: () => Navigator.of(context).pop(true),
+              child: Text('移除 $missing 个不存在的条目'),
+            ),
+        ],
+      ),
+    );
+    if (remove != true || !mounted) return;
+    try {
+      final removed = await service.removeMissing(report.issues);
+      invalidateLibraryViews(ref);
+      _showSnack('已移除 $removed 个失效条目');
+    } catch (e) {
+      _showSnack('移除失败：$e');
+    }
+  }
+
+  Future<void> _findDuplicateMovies() async {
+    if (_transferring) return;
+    setState(() => _transferring = true);
+    final repo = ref.read(mediaRepositoryProvider);
+    List<List<Media>> groups;
+    try {
+      groups = await repo.findDuplicateMovies();
+    } catch (e) {
+      _showSnack('查找失败：$e');
+      return;
+    } finally {
+      if (mounted) setState(() => _transferring = false);
+    }
+    if (!mounted) return;
+    if (groups.isEmpty) {
+      _showSnack('没有发现重复的电影');
+      return;
+    }
+
+    String location(Media media) {
+      final fullPath = media.fullPath ?? '';
+      return fullPath.isNotEmpty ? fullPath : media.path;
+    }
+
+    final extras = [for (final group in groups) ...group.skip(1)];
+    final remove = await showDialog<bool>(
+      context: context,
+      builder: (context) => AlertDialog(
+        title: Text('发现 ${groups.length} 组重复电影'),
+        content: SingleChildScrollView(
+          child: Text(
+            groups
+                .take(30)
+                .map(
+                  (group) => [
+                    group.first.year.isEmpty
+                        ? group.first.title
+                        : '${group.first.title} (${group.first.year})',
+                    '  保留：${location(group.first)}',
+                    ...group.skip(1).map((media) => '  重复：${location(media)}'),
+                  ].join('\n'),
+                )
+                .join('\n\n'),
+          ),
+        ),
+        actions: [
+          TextButton(
+            onPressed: () => Navigator.of(context).pop(false),
+            child: const Text('关闭'),
+          ),
+          FilledButton(
+            onPressed: () => Navigator.of(context).pop(true),
+            child: Text('移除 ${extras.length} 个重复条目'),
+          ),
+        ],
+      ),
+    );
+    if (remove != true || !mounted) return;
+    try {
+      for (final media in extras) {
+        await repo.deleteById(media.id);
+      }
+      invalidateLibraryViews(ref);
+      _showSnack('已移除 ${extras.length} 个重复条目（文件未删除）');
+    } catch (e) {
+      _showSnack('移除失败：$e');
+    }
+  }
+
+  Future<void> _compactDatabase() async {
+    if (_transferring) return;
+    setState(() => _transferring = true);
+    try {
+      final removed = await DatabaseTransferService(
+        ref.read(databaseProvider),
+      ).compact();
+      if (removed > 0) invalidateLibraryViews(ref);
+      _showSnack(removed > 0 ? '整理完成：清理了 $removed 条失效剧集' : '整理完成');
+    } catch (e) {
+      _showSnack('整理失败：$e');
+    } finally {
+      if (mounted) setState(() => _transferring = false);
+    }
+  }
+
   Future<String?> _stagedMigrationPath() async {
     final documents = await getApplicationDocumentsDirectory();
     final file = File(p.join(documents.path, 'open_filmly-macos.sqlite'));
@@ -392,6 +641,12 @@ class _ConfigPageState extends ConsumerState<ConfigPage> {
                   value: _autoScan,
                   onChanged: (v) => setState(() => _autoScan = v),
                 ),
+                _toggleRow(
+                  title: '定时重新扫描',
+                  subtitle: '应用运行期间每 $_periodicScanHours 小时增量扫描一次',
+                  value: _periodicScan,
+                  onChanged: (v) => setState(() => _periodicScan = v),
+                ),
                 const SizedBox(height: 4),
                 FilmlyGlassButton(
                   label: _scanning ? '扫描中…' : '保存并扫描目录',
@@ -542,6 +797,41 @@ class _ConfigPageState extends ConsumerState<ConfigPage> {
                   leading: _transferring ? _spinner() : null,
                   onTap: _transferring ? null : _exportDatabase,
                 ),
+                const SizedBox(height: 10),
+                FilmlyGlassButton(
+                  label: _transferring ? '处理中…' : '导出片单（M3U / CSV）',
+                  icon: _transferring ? null : Icons.playlist_play_rounded,
+                  leading: _transferring ? _spinner() : null,
+                  onTap: _transferring ? null : _exportPlaylist,
+                ),
+                const SizedBox(height: 10),
+                FilmlyGlassButton(
+                  label: _transferring ? '处理中…' : '媒体库统计',
+                  icon: _transferring ? null : Icons.insights_outlined,
+                  leading: _transferring ? _spinner() : null,
+                  onTap: _transferring ? null : _showLibraryStats,
+                ),
+                const SizedBox(height: 10),
+                FilmlyGlassButton(
+                  label: _transferring ? '处理中…' : '检查失效文件',
+                  icon: _transferring ? null : Icons.rule_folder_outlined,
+                  leading: _transferring ? _spinner() : null,
+                  onTap: _transferring ? null : _validateLibrary,
+                ),
+                const SizedBox(height: 10),
+                FilmlyGlassButton(
+                  label: _transferring ? '处理中…' : '查找重复电影',
+                  icon: _transferring ? null : Icons.content_copy_outlined,
+                  leading: _transferring ? _spinner() : null,
+                  onTap: _transferring ? null : _findDuplicateMovies,
+                ),
+                const SizedBox(height: 10),
+                FilmlyGlassButton(
+                  label: _transferring ? '处理中…' : '整理数据库',
+                  icon: _transferring ? null : Icons.cleaning_services_outlined,
+                  leading: _transferring ? _spinner() : null,
+                  onTap: _transferring ? null : _compactDatabase,
+                ),
               ],
             ),
             const SizedBox(height: 28),
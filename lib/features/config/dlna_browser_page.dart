@@ -0,0 +1,351 @@
+import 'package:flutter/material.dart';
+import 'package:flutter_riverpod/flutter_riverpod.dart';
+import 'package:go_router/go_router.dart';
+
+import '../../core/platform/open_player.dart';
+import '../../providers/data_providers.dart';
+import '../../providers/smb_providers.dart';
+import '../../services/dlna/dlna_service.dart';
+import '../../widgets/filmly_design.dart';
+import '../player/player_page.dart';
+
+/// Finds DLNA MediaServers on the LAN, walks their folders, and imports a
+/// folder's videos into the library. Nothing is saved as a source: servers
+/// are rediscovered each visit, and imported items play straight from their
+/// HTTP resource URLs.
+class DlnaBrowserPage extends ConsumerStatefulWidget {
+  const DlnaBrowserPage({super.key});
+
+  @override
+  ConsumerState<DlnaBrowserPage> createState() => _DlnaBrowserPageState();
+}
+
+class _DlnaBrowserPageState extends ConsumerState<DlnaBrowserPage> {
+  bool _discovering = false;
+  bool _loading = false;
+  bool _importing = false;
+  String? _error;
+
+  List<DlnaServer> _servers = [];
+  DlnaServer? _server;
+
+  /// Containers entered on [_server]; empty means its root.
+  final List<DlnaEntry> _stack = [];
+  List<DlnaEntry> _entries = [];
+
+  DlnaService get _dlna => ref.read(dlnaServiceProvider);
+
+  String get _titlePath => _stack.map((entry) => '/${entry.title}').join();
+
+  @override
+  void initState() {
+    super.initState();
+    _discover();
+  }
+
+  Future<void> _discover() async {
+    setState(() {
+      _discovering = true;
+      _error = null;
+    });
+    try {
+      final servers = await _dlna.discover();
+      if (!mounted) return;
+      setState(() {
+        _servers = servers;
+        _server = null;
+        _stack.clear();
+        _entries = [];
+      });
+    } catch (e) {
+      if (mounted) setState(() => _error = '搜索媒体服务器失败：$e');
+    } finally {
+      if (mounted) setState(() => _discovering = false);
+    }
+  }
+
+  /// Browses [container] (the root when null); false when it couldn't be read.
+  Future<bool> _open(DlnaServer server, [DlnaEntry? container]) async {
+    setState(() {
+      _loading = true;
+      _error = null;
+    });
+    try {
+      final entries = await _dlna.browse(
+        server,
+        objectId: container?.id ?? '0',
+      );
+      if (!mounted) return false;
+      setState(() {
+        if (_server != server) _stack.clear();
+        _server = server;
+        if (container != null) _stack.add(container);
+        _entries = entries
+            .where((entry) => entry.isContainer || entry.isVideo)
+            .toList(growable: false);
+      });
+      return true;
+    } catch (e) {
+      if (mounted) setState(() => _error = '读取目录失败：$e');
+      return false;
+    } finally {
+      if (mounted) setState(() => _loading = false);
+    }
+  }
+
+  Future<void> _up() async {
+    final server = _server;
+    if (server == null) return;
+    if (_stack.isEmpty) {
+      setState(() {
+        _server = null;
+        _entries = [];
+      });
+      return;
+    }
+    // _open re-pushes the parent; on failure stay where we were.
+    final previous = List.of(_stack);
+    _stack.removeLast();
+    final parent = _stack.isEmpty ? null : _stack.removeLast();
+    if (!await _open(server, parent) && mounted) {
+      setState(
+        () => _stack
+          ..clear()
+          ..addAll(previous),
+      );
+    }
+  }
+
+  Future<void> _importCurrent() async {
+    final server = _server;
+    if (server == null) return;
+    setState(() => _importing = true);
+    try {
+      final result = await ref
+          .read(dlnaLibraryImportProvider)
+          .importContainer(
+            server,
+            objectId: _stack.isEmpty ? '0' : _stack.last.id,
+            titlePath: _titlePath,
+          );
+      final config = await ref.read(configProvider.future);
+      var metadataMessage = '';
+      if (config.tmdbApiKey.isNotEmpty && result.mediaIds.isNotEmpty) {
+        final metadataResult = await ref
+            .read(libraryMetadataSyncProvider)
+            .enrichByIds(
+              mediaIds: result.mediaIds,
+              apiKey: config.tmdbApiKey,
+              geminiApiKey: config.geminiApiKey,
+            );
+        metadataMessage =
+            '，元数据更新 ${metadataResult.updatedItems}/${metadataResult.requestedItems}';
+      }
+      invalidateLibraryViews(ref);
+
+      if (!mounted) return;
+      ScaffoldMessenger.of(context).showSnackBar(
+        SnackBar(
+          content: Text(
+            '已导入 ${result.importedItems} 个媒体'
+            '（电影 ${result.movieCount} / 剧集 ${result.tvCount}）'
+            '$metadataMessage',
+          ),
+        ),
+      );
+    } catch (e) {
+      if (!mounted) return;
+      ScaffoldMessenger.of(
+        context,
+      ).showSnackBar(SnackBar(content: Text('导入失败：$e')));
+    } finally {
+      if (mounted) setState(() => _importing = false);
+    }
+  }
+
+  Future<void> _play(DlnaEntry entry) async {
+    final url = entry.resourceUrl;
+    if (url == null) return;
+    await openPlayer(
+      context,
+      PlayerArgs(uri: url.toString(), title: entry.title),
+    );
+  }
+
+  @override
+  Widget build(BuildContext context) {
+    final server = _server;
+    final crumb = server == null
+        ? '局域网媒体服务器'
+        : [server.name, ..._stack.map((entry) => entry.title)].join(' / ');
+
+    return Scaffold(
+      backgroundColor: FilmlyPalette.background,
+      body: SafeArea(
+        child: Padding(
+          padding: const EdgeInsets.fromLTRB(32, 28, 32, 24),
+          child: Column(
+            crossAxisAlignment: CrossAxisAlignment.start,
+            children: [
+              FilmlyInlineHeader(
+                leading: FilmlyIconButton(
+                  key: const Key('dlna_back_button'),
+                  icon: Icons.chevron_left_rounded,
+                  onTap: () =>
+                      context.canPop() ? context.pop() : context.go('/sources'),
+                ),
+                title: 'DLNA',
+                subtitle: crumb,
+              ),
+              const SizedBox(height: 24),
+              Wrap(
+                spacing: 12,
+                runSpacing: 12,
+                children: [
+                  if (server == null)
+                    FilmlyGlassButton(
+                      key: const Key('dlna_discover_button'),
+                      label: _discovering ? '搜索中…' : '重新搜索',
+                      icon: _discovering ? null : Icons.radar_rounded,
+                      leading: _discovering ? _spinner() : null,
+                      onTap: _discovering ? null : _discover,
+                    )
+                  else ...[
+                    FilmlyGlassButton(
+                      label: '上级目录',
+                      icon: Icons.arrow_upward_rounded,
+                      onTap: _loading ? null : _up,
+                    ),
+                    FilmlyGlassButton(
+                      key: const Key('dlna_import_button'),
+                      label: _importing ? '导入中…' : '导入此文件夹',
+                      icon: _importing ? null : Icons.download_rounded,
+                      accent: true,
+                      leading: _importing ? _spinner() : null,
+                      onTap: _loading || _importing ? null : _importCurrent,
+                    ),
+                  ],
+                ],
+              ),
+              if (_error != null) ...[
+                const SizedBox(height: 16),
+                Text(
+                  _error!,
+                  style: const TextStyle(
+                    color: Color(0xFFFF6B7D),
+                    fontSize: 13,
+                  ),
+                ),
+              ],
+              const SizedBox(height: 20),
+              Expanded(
+                child: server == null ? _buildServers() : _buildEntries(),
+              ),
+            ],
+          ),
+        ),
+      ),
+    );
+  }
+
+  Widget _buildServers() {
+    if (_discovering) {
+      return const Center(child: CircularProgressIndicator());
+    }
+    if (_servers.isEmpty) {
+      return const Center(
+        child: Text(
+          '未发现 DLNA 媒体服务器，请确认设备在同一局域网内',
+          style: TextStyle(color: FilmlyPalette.textMuted),
+        ),
+      );
+    }
+    return ListView.separated(
+      physics: const BouncingScrollPhysics(),
+      itemCount: _servers.length,
+      separatorBuilder: (_, _) => const SizedBox(height: 8),
+      itemBuilder: (context, index) {
+        final server = _servers[index];
+        return _tile(
+          key: Key('dlna_server_${server.name}'),
+          icon: Icons.dns_rounded,
+          title: server.name,
+          trailing: Icons.chevron_right_rounded,
+          onTap: () => _open(server),
+        );
+      },
+    );
+  }
+
+  Widget _buildEntries() {
+    if (_loading) {
+      return const Center(child: CircularProgressIndicator());
+    }
+    if (_entries.isEmpty) {
+      return const Center(
+        child: Text('空目录', style: TextStyle(color: FilmlyPalette.textMuted)),
+      );
+    }
+    return ListView.separated(
+      physics: const BouncingScrollPhysics(),
+      itemCount: _entries.length,
+      separatorBuilder: (_, _) => const SizedBox(height: 8),
+      itemBuilder: (context, index) {
+        final entry = _entries[index];
+        return _tile(
+          key: Key('dlna_entry_${entry.id}'),
+          icon: entry.isContainer ? Icons.folder_rounded : Icons.movie_rounded,
+          title: entry.title,
+          trailing: entry.isContainer
+              ? Icons.chevron_right_rounded
+              : Icons.play_circle_outline_rounded,
+          onTap: entry.isContainer
+              ? () => _open(_server!, entry)
+              : () => _play(entry),
+        );
+      },
+    );
+  }
+
+  Widget _tile({
+    required Key key,
+    required IconData icon,
+    required String title,
+    required IconData trailing,
+    required VoidCallback onTap,
+  }) {
+    return GestureDetector(
+      key: key,
+      onTap: onTap,
+      child: FilmlyGlassPanel(
+        borderRadius: BorderRadius.circular(18),
+        color: FilmlyPalette.surface,
+        padding: const EdgeInsets.symmetric(horizontal: 16, vertical: 13),
+        child: Row(
+          children: [
+            Icon(icon, color: const Color(0xFF62D6B4), size: 22),
+            const SizedBox(width: 14),
+            Expanded(
+              child: Text(
+                title,
+                maxLines: 1,
+                overflow: TextOverflow.ellipsis,
+                style: const TextStyle(
+                  color: FilmlyPalette.textPrimary,
+                  fontSize: 14,
+                ),
+              ),
+            ),
+            Icon(trailing, color: FilmlyPalette.textSecondary, size: 20),
+          ],
+        ),
+      ),
+    );
+  }
+
+  Widget _spinner() => const SizedBox(
+    width: 16,
+    height: 16,
+    child: CircularProgressIndicator(strokeWidth: 2),
+  );
+}
@@ -0,0 +1,320 @@
+import 'package:flutter/material.dart';
+import 'package:flutter_riverpod/flutter_riverpod.dart';
+import 'package:go_router/go_router.dart';
+
+import '../../data/models/app_config.dart';
+import '../../data/models/resource_source.dart';
+import '../../providers/data_providers.dart';
+import '../../providers/smb_providers.dart';
+import '../../services/plex/plex_service.dart';
+import '../../widgets/filmly_design.dart';
+
+/// Connect to a Plex Media Server with an `X-Plex-Token` and import its
+/// library in one step, like the Emby page.
+class PlexBrowserPage extends ConsumerStatefulWidget {
+  const PlexBrowserPage({super.key, this.sourceId});
+
+  final String? sourceId;
+
+  @override
+  ConsumerState<PlexBrowserPage> createState() => _PlexBrowserPageState();
+}
+
+class _PlexBrowserPageState extends ConsumerState<PlexBrowserPage> {
+  final _urlCtrl = TextEditingController();
+  final _tokenCtrl = TextEditingController();
+
+  bool _connecting = false;
+  bool _connected = false;
+  bool _importing = false;
+  bool _prefilled = false;
+  String? _error;
+  String? _status;
+
+  PlexService get _plex => ref.read(plexServiceProvider);
+
+  ResourceSource? _sourceFromConfig(AppConfig config) {
+    final sourceId = widget.sourceId;
+    if (sourceId != null) {
+      for (final source in config.resourceSources) {
+        if (source.id == sourceId) return source;
+      }
+    }
+    for (final source in config.resourceSources) {
+      if (source.type == ResourceSourceType.plex) return source;
+    }
+    return null;
+  }
+
+  Future<void> _saveSource() async {
+    final config = ref.read(configProvider).asData?.value;
+    if (config == null) return;
+    final existing = _sourceFromConfig(config);
+    final source = ResourceSource(
+      id:
+          existing?.id ??
+          widget.sourceId ??
+          ResourceSource.newId(ResourceSourceType.plex),
+      name: existing?.name ?? '我的 Plex',
+      type: ResourceSourceType.plex,
+      endpoint: _urlCtrl.text.trim(),
+      password: _tokenCtrl.text.trim(),
+      importedPaths: existing?.importedPaths ?? const [],
+    );
+    final sources = [...config.resourceSources];
+    final index = sources.indexWhere((item) => item.id == source.id);
+    if (index >= 0) {
+      sources[index] = source;
+    } else {
+      sources.add(source);
+    }
+    await ref
+        .read(configProvider.notifier)
+        .save(
+          config.copyWith(
+            plexUrl: source.endpoint,
+            plexToken: source.password,
+            resourceSources: sources,
+          ),
+        );
+  }
+
+  @override
+  void dispose() {
+    _urlCtrl.dispose();
+    _tokenCtrl.dispose();
+    super.dispose();
+  }
+
+  void _prefillFromConfig() {
+    if (_prefilled) return;
+    final config = ref.read(configProvider).asData?.value;
+    if (config == null) return;
+    _prefilled = true;
+    final source = _sourceFromConfig(config);
+    if (_urlCtrl.text.isEmpty) {
+      _urlCtrl.text = source?.endpoint ?? config.plexUrl;
+    }
+    if (_tokenCtrl.text.isEmpty) {
+      _tokenCtrl.text = source?.password ?? config.plexToken;
+    }
+  }
+
+  Future<void> _connect() async {
+    final url = _urlCtrl.text.trim();
+    final token = _tokenCtrl.text.trim();
+    if (url.isEmpty || token.isEmpty) {
+      setState(() => _error = '请输入服务器地址和令牌');
+      return;
+    }
+    setState(() {
+      _connecting = true;
+      _error = null;
+    });
+    try {
+      await _plex.connect(PlexConfig(url: url, token: token));
+
+      await _saveSource();
+
+      if (!mounted) return;
+      setState(() {
+        _connected = true;
+        _status = '连接成功，可导入媒体库。';
+      });
+    } catch (e) {
+      if (mounted) setState(() => _error = '连接失败：$e');
+    } finally {
+      if (mounted) setState(() => _connecting = false);
+    }
+  }
+
+  Future<void> _import() async {
+    setState(() {
+      _importing = true;
+      _error = null;
+    });
+    try {
+      final result = await ref.read(plexLibraryImportProvider).importLibrary();
+
+      final config = ref.read(configProvider).asData?.value;
+      var metaMsg = '';
+      if (config != null &&
+          config.tmdbApiKey.isNotEmpty &&
+          result.mediaIds.isNotEmpty) {
+        // Plex supplies posters and summaries; only enrich items without one.
+        final missing = await ref
+            .read(mediaRepositoryProvider)
+            .getIdsWithoutPoster();
+        final toEnrich = missing.where(result.mediaIds.contains).toList();
+        if (toEnrich.isNotEmpty) {
+          final meta = await ref
+              .read(libraryMetadataSyncProvider)
+              .enrichByIds(
+                mediaIds: toEnrich,
+                apiKey: config.tmdbApiKey,
+                geminiApiKey: config.geminiApiKey,
+              );
+          metaMsg = '，补全元数据 ${meta.updatedItems} 项';
+        }
+      }
+
+      invalidateLibraryViews(ref);
+      if (!mounted) return;
+      setState(() {
+        _status =
+            '导入完成：电影 ${result.movieCount} / 剧集 ${result.tvCount}'
+            '（${result.episodeCount} 集）$metaMsg';
+      });
+    } catch (e) {
+      if (!mounted) return;
+      setState(() => _error = '导入失败：$e');
+    } finally {
+      if (mounted) setState(() => _importing = false);
+    }
+  }
+
+  void _goBack() {
+    if (Navigator.of(context).canPop()) {
+      context.pop();
+      return;
+    }
+    context.go('/sources');
+  }
+
+  @override
+  Widget build(BuildContext context) {
+    _prefillFromConfig();
+    return Scaffold(
+      backgroundColor: FilmlyPalette.background,
+      body: SafeArea(
+        child: Padding(
+          padding: const EdgeInsets.fromLTRB(32, 28, 32, 24),
+          child: ListView(
+            physics: const BouncingScrollPhysics(),
+            children: [
+              FilmlyInlineHeader(
+                leading: FilmlyIconButton(
+                  icon: Icons.chevron_left_rounded,
+                  onTap: _goBack,
+                ),
+                title: 'Plex',
+                subtitle: _connected ? '已连接' : '连接到 Plex Media Server',
+              ),
+              const SizedBox(height: 24),
+              _field(
+                _urlCtrl,
+                '服务器地址',
+                'http://192.168.1.10:32400',
+                key: const Key('plex_url_input'),
+              ),
+              const SizedBox(height: 14),
+              _field(
+                _tokenCtrl,
+                '令牌',
+                'X-Plex-Token',
+                key: const Key('plex_token_input'),
+                obscure: true,
+              ),
+              if (_error != null) ...[
+                const SizedBox(height: 16),
+                Text(
+                  _error!,
+                  style: const TextStyle(
+                    color: Color(0xFFFF6B7D),
+                    fontSize: 13,
+                  ),
+                ),
+              ],
+              if (_status != null) ...[
+                const SizedBox(height: 16),
+                Text(
+                  _status!,
+                  style: const TextStyle(
+                    color: FilmlyPalette.textSecondary,
+                    fontSize: 13,
+                  ),
+                ),
+              ],
+              const SizedBox(height: 24),
+              Wrap(
+                spacing: 12,
+                runSpacing: 12,
+                children: [
+                  FilmlyGlassButton(
+                    key: const Key('plex_connect_button'),
+                    label: _connecting ? '连接中…' : (_connected ? '重新连接' : '连接'),
+                    icon: _connecting ? null : Icons.link_rounded,
+                    accent: !_connected,
+                    leading: _connecting ? _spinner() : null,
+                    onTap: _connecting ? null : _connect,
+                  ),
+                  if (_connected)
+                    FilmlyGlassButton(
+                      key: const Key('plex_import_button'),
+                      label: _importing ? '导入中…' : '导入媒体库',
+                      icon: _importing ? null : Icons.download_rounded,
+                      accent: true,
+                      leading: _importing ? _spinner() : null,
+                      onTap: _importing ? null : _import,
+                    ),
+                ],
+              ),
+            ],
+          ),
+        ),
+      ),
+    );
+  }
+
+  Widget _spinner() => const SizedBox(
+    width: 16,
+    height: 16,
+    child: CircularProgressIndicator(strokeWidth: 2),
+  );
+
+  Widget _field(
+    TextEditingController controller,
+    String label,
+    String hint, {
+    Key? key,
+    bool obscure = false,
+  }) {
+    return Column(
+      crossAxisAlignment: CrossAxisAlignment.start,
+      children: [
+        Text(
+          label,
+          style: const TextStyle(
+            color: FilmlyPalette.textSecondary,
+            fontSize: 13,
+            fontWeight: FontWeight.w500,
+          ),
+        ),
+        const SizedBox(height: 8),
+        FilmlyGlassPanel(
+          borderRadius: BorderRadius.circular(14),
+          color: FilmlyPalette.surface,
+          padding: const EdgeInsets.symmetric(horizontal: 14, vertical: 4),
+          child: TextField(
+            key: key,
+            controller: controller,
+            obscureText: obscure,
+            style: const TextStyle(
+              color: FilmlyPalette.textPrimary,
+              fontSize: 15,
+            ),
+            cursorColor: FilmlyPalette.accent,
+            decoration: InputDecoration(
+              hintText: hint,
+              hintStyle: const TextStyle(
+                color: FilmlyPalette.textMuted,
+                fontSize: 15,
+              ),
+              border: InputBorder.none,
+            ),
+          ),
+        ),
+      ],
+    );
+  }
+}
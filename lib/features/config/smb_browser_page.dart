@@ -12,6 +12,8 @@ import '../../providers/data_providers.dart';
 import '../../providers/smb_providers.dart';
 import '../../services/playback/external_subtitle_finder.dart';
 import '../../services/playback/playback_source_resolver.dart';
+import '../../services/smb/smb_listing_cache.dart';
+import '../../services/smb/smb_path.dart';
 import '../../services/smb/smb_proxy_server.dart';
 import '../../services/smb/smb_service.dart';
 import '../../widgets/filmly_design.dart';
@@ -68,6 +70,9 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
   /// Current directory path as a stack of folders; empty means the share list.
   final List<SmbFile> _stack = [];
   List<SmbFile> _entries = [];
+
+  /// Folder listings reused while browsing; see [SmbListingCache].
+  final _listingCache = SmbListingCache();
   final Set<String> _selectedPaths = {};
 
   SmbService get _smb => ref.read(smbServiceProvider);
@@ -159,6 +164,14 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
   }
 
   Future<void> _connect() async {
+    // Accept a pasted smb:// URL or UNC path and spread it over the form.
+    final pasted = SmbAddress.tryParse(_hostCtrl.text);
+    if (pasted != null) {
+      _hostCtrl.text = pasted.host;
+      if (pasted.username.isNotEmpty) _userCtrl.text = pasted.username;
+      if (pasted.domain.isNotEmpty) _domainCtrl.text = pasted.domain;
+      if (!pasted.path.isRoot) _shareCtrl.text = pasted.path.serverPath;
+    }
     final host = _hostCtrl.text.trim();
     if (host.isEmpty) {
       setState(() => _error = '请输入 NAS 主机地址');
@@ -170,17 +183,24 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
     });
     try {
       final user = _userCtrl.text.trim();
-      await _smb.connect(
-        SmbConfig(
-          host: host,
-          username: user.isEmpty ? 'guest' : user,
-          password: _passCtrl.text,
-          domain: _domainCtrl.text.trim(),
-        ),
+      final typed = SmbConfig(
+        host: host,
+        username: user.isEmpty ? 'guest' : user,
+        password: _passCtrl.text,
+        domain: _domainCtrl.text.trim(),
       );
+      final config = await _smb.connectWithAny([
+        typed,
+        ..._savedCredentialsFor(host, except: typed),
+      ]);
       await _proxy.start();
       if (!mounted) return;
-      setState(() => _connected = true);
+      setState(() {
+        _connected = true;
+        _userCtrl.text = config.username;
+        _passCtrl.text = config.password;
+        _domainCtrl.text = config.domain;
+      });
 
       // Persist both the legacy resolver fields and the selected source.
       // Persist credentials best-effort; connection already succeeded.
@@ -204,12 +224,42 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
     }
   }
 
+  /// Other logins saved for [host] (e.g. per-user profiles on one NAS), tried
+  /// after the typed credentials so a stale form still connects.
+  List<SmbConfig> _savedCredentialsFor(
+    String host, {
+    required SmbConfig except,
+  }) {
+    final config = ref.read(configProvider).asData?.value;
+    if (config == null) return const [];
+    final seen = {(except.username, except.password, except.domain)};
+    final candidates = <SmbConfig>[];
+    for (final source in config.resourceSources) {
+      if (source.type != ResourceSourceType.smb ||
+          source.endpoint.trim().toLowerCase() != host.toLowerCase()) {
+        continue;
+      }
+      final username = source.username.isEmpty ? 'guest' : source.username;
+      if (!seen.add((username, source.password, source.domain))) continue;
+      candidates.add(
+        SmbConfig(
+          host: host,
+          username: username,
+          password: source.password,
+          domain: source.domain,
+        ),
+      );
+    }
+    return candidates;
+  }
+
   Future<void> _disconnect() async {
     await _smb.disconnect();
     if (!mounted) return;
     setState(() {
       _connected = false;
       _entries = [];
+      _listingCache.clear();
       _stack.clear();
       _selectedPaths.clear();
       _error = null;
@@ -316,15 +366,15 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
   /// enumeration — which many NAS servers don't expose. The opened folder
   /// becomes the new browse root.
   Future<void> _openShareByName(String raw) async {
-    final name = raw.trim().replaceAll(RegExp(r'^/+|/+$'), '');
-    if (name.isEmpty) return;
+    final target = SmbPath.normalize(raw.trim());
+    if (target.isRoot) return;
     setState(() {
       _loading = true;
       _error = null;
       _selectedPaths.clear();
     });
     try {
-      final folder = await _smb.openFolder('/$name');
+      final folder = await _smb.openFolder(target.serverPath);
       final children = await _smb.listChildren(folder);
       if (!mounted) return;
       setState(() {
@@ -341,7 +391,7 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
   }
 
   Future<void> _enter(SmbFile folder) => _load(
-    () => _smb.listChildrenByPath(folder.path),
+    () => _listCached(folder.path),
     push: folder,
     clearSelection: true,
   );
@@ -352,7 +402,23 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
     _selectedPaths.clear();
     return _stack.isEmpty
         ? _loadShares()
-        : _load(() => _smb.listChildrenByPath(_stack.last.path));
+        : _load(() => _listCached(_stack.last.path));
+  }
+
+  Future<List<SmbFile>> _listCached(String path) async {
+    final cached = _listingCache.get(path);
+    if (cached != null) return cached;
+    final entries = await _smb.listChildrenByPath(path);
+    _listingCache.put(path, entries);
+    return entries;
+  }
+
+  /// Drops the current folder's cached listing and reads it again.
+  Future<void> _refresh() {
+    if (_stack.isEmpty) return _loadShares();
+    final path = _stack.last.path;
+    _listingCache.remove(path);
+    return _load(() => _listCached(path));
   }
 
   /// Shared loader: runs [fetch], sorts results, and manages loading/error.
@@ -382,6 +448,42 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
     }
   }
 
+  /// Probes the current folder with [SmbService.checkAccess] and reports
+  /// what this login may do there, so a read-only share is known up front.
+  Future<void> _checkAccess() async {
+    final folder = _currentFolder;
+    if (folder == null) return;
+    final messenger = ScaffoldMessenger.of(context);
+    try {
+      final report = await _smb.checkAccess(folder.path);
+      if (!mounted) return;
+      messenger.showSnackBar(SnackBar(content: Text(_accessLabel(report))));
+    } catch (e) {
+      if (!mounted) return;
+      messenger.showSnackBar(SnackBar(content: Text('检查权限失败：$e')));
+    }
+  }
+
+  static String _accessLabel(SmbAccessReport report) {
+    if (!report.exists) return '文件夹不存在';
+    if (!report.canRead) return '无权读取此文件夹';
+    if (report.isReadOnly) return '只读共享：可以浏览和播放，无法写入';
+    final leftover = report.leftoverProbe;
+    if (leftover != null) return '可读写（测试文件未能删除，请手动删除：$leftover）';
+    return '可读写';
+  }
+
+  /// Flips [SmbService.showHidden] and reloads the current listing.
+  Future<void> _toggleHidden() {
+    setState(() {
+      _smb.showHidden = !_smb.showHidden;
+      _listingCache.clear();
+    });
+    return _stack.isEmpty
+        ? _loadShares()
+        : _load(() => _listCached(_stack.last.path));
+  }
+
   bool _isDirectoryEntry(SmbFile entry) {
     return _stack.isEmpty || entry.isDirectory();
   }
@@ -524,7 +626,9 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
                       context.canPop() ? context.pop() : context.go('/sources'),
                 ),
                 title: 'SMB / NAS',
-                subtitle: _connected ? '已连接 · $crumb' : '连接局域网内的 NAS 存储',
+                subtitle: _connected
+                    ? '已连接$_connectTimeLabel · $crumb'
+                    : '连接局域网内的 NAS 存储',
                 trailing: _connected
                     ? FilmlyIconButton(
                         key: const Key('smb_disconnect_button'),
@@ -544,6 +648,16 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
     );
   }
 
+  /// `（320 ms）` after the status, so a slow NAS login is visible; sessions
+  /// that dropped and were re-established add `，已重连 2 次`.
+  String get _connectTimeLabel {
+    final timings = _smb.lastConnectTimings;
+    if (timings == null) return '';
+    final reconnects = _smb.reconnectCount;
+    final suffix = reconnects == 0 ? '' : '，已重连 $reconnects 次';
+    return '（${timings.total.inMilliseconds} ms$suffix）';
+  }
+
   Widget _buildConnectForm() {
     return SingleChildScrollView(
       physics: const BouncingScrollPhysics(),
@@ -553,7 +667,7 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
           _field(
             _hostCtrl,
             '主机地址',
-            '192.168.1.100',
+            '192.168.1.100 或 smb://nas/Media',
             key: const Key('smb_host_input'),
             onSubmit: _connect,
           ),
@@ -641,6 +755,27 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
                   : Icons.select_all_rounded,
               onTap: _loading ? null : _toggleSelectAll,
             ),
+            if (_currentFolder != null)
+              FilmlyGlassButton(
+                key: const Key('smb_check_access_button'),
+                label: '检查权限',
+                icon: Icons.lock_outline_rounded,
+                onTap: _loading ? null : _checkAccess,
+              ),
+            FilmlyGlassButton(
+              key: const Key('smb_refresh_button'),
+              label: '刷新',
+              icon: Icons.refresh_rounded,
+              onTap: _loading ? null : _refresh,
+            ),
+            FilmlyGlassButton(
+              key: const Key('smb_toggle_hidden_button'),
+              label: _smb.showHidden ? '隐藏系统文件' : '显示隐藏文件',
+              icon: _smb.showHidden
+                  ? Icons.visibility_off_rounded
+                  : Icons.visibility_rounded,
+              onTap: _loading ? null : _toggleHidden,
+            ),
           ],
         ),
         if (_selectedPaths.isNotEmpty) ...[
@@ -720,6 +855,15 @@ class _SmbBrowserPageState extends ConsumerState<SmbBrowserPage> {
                   ),
                 ),
               ),
+              if (SmbService.isOfflineEntry(entry))
+                const Padding(
+                  padding: EdgeInsets.only(right: 8),
+                  child: Icon(
+                    Icons.cloud_outlined,
+                    color: FilmlyPalette.textMuted,
+                    size: 18,
+                  ),
+                ),
               if (!isDir && entry.size > 0)
                 Padding(
                   padding: const EdgeInsets.only(right: 8),
@@ -12,6 +12,7 @@ import '../../core/platform/platform_capabilities.dart';
 import '../../data/models/episode.dart';
 import '../../data/models/media.dart';
 import '../../data/models/playback_progress.dart';
+import '../../data/models/release_info.dart';
 import '../../providers/data_providers.dart';
 import '../../providers/smb_providers.dart';
 import '../../services/library/media_library_entry_factory.dart';
@@ -105,6 +106,9 @@ class MediaDetailPage extends ConsumerWidget {
     // Prefer a show-root path (not a single season folder) for TV libraries
     // that were merged from S01/S02/… directories.
     final displayPath = _displaySourcePath(ref, media);
+    final releaseBadges = media.type == MediaType.tv
+        ? const <String>[]
+        : ReleaseInfo.parse(displayPath).badges;
 
     final isMobile = PlatformCapabilities.isMobile;
     final pagePad = isMobile ? 16.0 : 32.0;
@@ -160,6 +164,10 @@ class MediaDetailPage extends ConsumerWidget {
                     _CastRow(mediaId: media.id),
                     if (displayPath.isNotEmpty)
                       _infoBlock(context, '片源路径', displayPath),
+                    if (releaseBadges.isNotEmpty) ...[
+                      const SizedBox(height: 16),
+                      _infoBlock(context, '片源规格', releaseBadges.join(' · ')),
+                    ],
                     if (media.fileHash != null &&
                         media.fileHash!.isNotEmpty) ...[
                       const SizedBox(height: 16),
@@ -552,7 +560,7 @@ class MediaDetailPage extends ConsumerWidget {
 }
 
 /// NetEase-style season tabs with one horizontal row for the selected season.
-class _SeasonEpisodeBrowser extends StatefulWidget {
+class _SeasonEpisodeBrowser extends ConsumerStatefulWidget {
   const _SeasonEpisodeBrowser({
     required this.seasons,
     required this.show,
@@ -566,10 +574,11 @@ class _SeasonEpisodeBrowser extends StatefulWidget {
   final ValueChanged<Episode> onShowDetails;
 
   @override
-  State<_SeasonEpisodeBrowser> createState() => _SeasonEpisodeBrowserState();
+  ConsumerState<_SeasonEpisodeBrowser> createState() =>
+      _SeasonEpisodeBrowserState();
 }
 
-class _SeasonEpisodeBrowserState extends State<_SeasonEpisodeBrowser> {
+class _SeasonEpisodeBrowserState extends ConsumerState<_SeasonEpisodeBrowser> {
   late int _selectedSeason;
 
   @override
@@ -591,6 +600,16 @@ class _SeasonEpisodeBrowserState extends State<_SeasonEpisodeBrowser> {
     final season = widget.seasons.firstWhere(
       (item) => item.number == _selectedSeason,
     );
+    final aired = ref
+        .watch(
+          airedEpisodeCountProvider((
+            showId: widget.show.id,
+            season: season.number,
+          )),
+        )
+        .asData
+        ?.value;
+    final missing = season.missingEpisodes(aired);
 
     return Column(
       crossAxisAlignment: CrossAxisAlignment.start,
@@ -639,6 +658,19 @@ class _SeasonEpisodeBrowserState extends State<_SeasonEpisodeBrowser> {
             },
           ),
         ),
+        if (missing.isNotEmpty)
+          Padding(
+            padding: const EdgeInsets.only(top: 8),
+            child: Text(
+              missing.length > 8
+                  ? '缺少第 ${missing.take(8).join('、')} 等 ${missing.length} 集'
+                  : '缺少第 ${missing.join('、')} 集',
+              style: const TextStyle(
+                color: FilmlyPalette.textMuted,
+                fontSize: 13,
+              ),
+            ),
+          ),
         const SizedBox(height: 14),
         AnimatedSwitcher(
           duration: const Duration(milliseconds: 220),
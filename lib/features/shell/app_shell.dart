@@ -1,3 +1,5 @@
+import 'dart:async';
+
 import 'package:flutter/cupertino.dart';
 import 'package:flutter/material.dart';
 import 'package:flutter/services.dart';
@@ -16,7 +18,8 @@ import '../../widgets/media_command_palette.dart';
 
 /// Persistent macOS-style split view: a light sidebar on the left (brand +
 /// library nav) and the routed content on the right — matching NetEase 爆米花's
-/// Mac layout. Also hosts the Cmd/Ctrl+F search shortcut + startup auto-scan.
+/// Mac layout. Also hosts the Cmd/Ctrl+F search shortcut + startup auto-scan,
+/// repeated every `autoScanIntervalHours` while the app stays open.
 class AppShell extends ConsumerStatefulWidget {
   const AppShell({super.key, required this.child});
 
@@ -40,6 +43,9 @@ class AppShell extends ConsumerStatefulWidget {
 
 class _AppShellState extends ConsumerState<AppShell> {
   bool _startupScanStarted = false;
+  bool _scanRunning = false;
+  Timer? _scanTimer;
+  int _scanIntervalHours = 0;
 
   @override
   void initState() {
@@ -47,10 +53,49 @@ class _AppShellState extends ConsumerState<AppShell> {
     WidgetsBinding.instance.addPostFrameCallback((_) => _runStartupScan());
   }
 
+  @override
+  void dispose() {
+    _scanTimer?.cancel();
+    super.dispose();
+  }
+
   Future<void> _runStartupScan() async {
     if (_startupScanStarted) return;
     _startupScanStarted = true;
+    await _runAutoScan();
+    if (!mounted) return;
+    try {
+      final config = await ref.read(configProvider.future);
+      if (!mounted) return;
+      _armScanTimer(config.autoScanIntervalHours);
+      // Settings can change the interval while the app stays open.
+      ref.listenManual(configProvider, (_, next) {
+        final hours = next.asData?.value.autoScanIntervalHours;
+        if (hours != null && hours != _scanIntervalHours) {
+          _armScanTimer(hours);
+        }
+      });
+    } catch (_) {
+      // best-effort
+    }
+  }
+
+  /// Replaces the periodic rescan; an interval of 0 turns it off.
+  void _armScanTimer(int hours) {
+    _scanTimer?.cancel();
+    _scanTimer = null;
+    _scanIntervalHours = hours;
+    if (hours <= 0) return;
+    _scanTimer = Timer.periodic(Duration(hours: hours), (_) => _runAutoScan());
+  }
+
+  /// Skips a tick while the previous scan is still running, so a slow NAS
+  /// never accumulates overlapping scans.
+  Future<void> _runAutoScan() async {
+    if (_scanRunning) return;
+    _scanRunning = true;
     try {
+      // Re-read the config each time: settings may have changed since launch.
       final config = await ref.read(configProvider.future);
       final result = await ref.read(libraryAutoScanProvider).run(config);
       if (!mounted || !result.hasChanges) return;
@@ -65,6 +110,8 @@ class _AppShellState extends ConsumerState<AppShell> {
       );
     } catch (_) {
       // best-effort
+    } finally {
+      _scanRunning = false;
     }
   }
 
@@ -116,6 +116,21 @@ class AddResourceSourcePage extends StatelessWidget {
                       onTap: () =>
                           _openEditor(context, ResourceSourceType.jellyfin),
                     ),
+                    _SourceOptionTile(
+                      key: const Key('source_card_plex'),
+                      icon: Icons.smart_display_rounded,
+                      title: 'Plex',
+                      subtitle: '用 X-Plex-Token 导入 Plex 媒体库',
+                      onTap: () =>
+                          _openEditor(context, ResourceSourceType.plex),
+                    ),
+                    _SourceOptionTile(
+                      key: const Key('source_card_dlna'),
+                      icon: Icons.cast_connected_rounded,
+                      title: 'DLNA',
+                      subtitle: '搜索局域网内的 DLNA 媒体服务器',
+                      onTap: () => context.go('/dlna'),
+                    ),
                   ],
                 ),
                 const SizedBox(height: 22),
@@ -186,6 +201,10 @@ class _ResourceSourceEditorPageState
       widget.type == ResourceSourceType.emby ||
       widget.type == ResourceSourceType.jellyfin;
 
+  /// Plex signs in with a token rather than a username and password; it is
+  /// kept in the source's password field.
+  bool get _isPlex => widget.type == ResourceSourceType.plex;
+
   @override
   void dispose() {
     _nameCtrl.dispose();
@@ -236,6 +255,9 @@ class _ResourceSourceEditorPageState
       _userCtrl.text = config.smbUsername;
       _passCtrl.text = config.smbPassword;
       _pathCtrl.text = config.smbShare;
+    } else if (_isPlex) {
+      _endpointCtrl.text = config.plexUrl;
+      _passCtrl.text = config.plexToken;
     } else {
       _endpointCtrl.text = config.embyUrl;
       _userCtrl.text = config.embyUsername;
@@ -330,6 +352,8 @@ class _ResourceSourceEditorPageState
           smbPassword: source.password,
           smbShare: source.path == '/' ? '' : source.path,
         );
+      } else if (_isPlex) {
+        next = next.copyWith(plexUrl: endpoint, plexToken: source.password);
       } else {
         next = next.copyWith(
           embyUrl: endpoint,
@@ -441,7 +465,11 @@ class _ResourceSourceEditorPageState
               ),
               label: '地址',
               controller: _endpointCtrl,
-              hint: _isWebDav ? '请输入 IP 或域名' : '请输入 NAS IP 或域名',
+              hint: switch (widget.type) {
+                ResourceSourceType.webdav => '请输入 IP 或域名',
+                ResourceSourceType.plex => 'http://192.168.1.10:32400',
+                _ => '请输入 NAS IP 或域名',
+              },
             ),
             if (_isWebDav || _isNetwork) ...[
               const SizedBox(height: 14),
@@ -455,6 +483,16 @@ class _ResourceSourceEditorPageState
                 keyboardType: TextInputType.number,
               ),
             ],
+            if (_isPlex) ...[
+              const SizedBox(height: 14),
+              _ResourceField(
+                key: const Key('plex_token_input'),
+                label: '令牌',
+                controller: _passCtrl,
+                hint: 'X-Plex-Token',
+                obscureText: _obscure,
+              ),
+            ],
             if (_isNetwork || _isServer) ...[
               const SizedBox(height: 14),
               _ResourceField(label: '用户名', controller: _userCtrl, hint: '选填'),
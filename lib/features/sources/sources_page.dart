@@ -35,6 +35,8 @@ class _SourcesPageState extends ConsumerState<SourcesPage> {
         context.go(_sourceLocation('/smb', source.id));
       case ResourceSourceType.emby || ResourceSourceType.jellyfin:
         context.go(_sourceLocation('/emby', source.id));
+      case ResourceSourceType.plex:
+        context.go(_sourceLocation('/plex', source.id));
       case ResourceSourceType.cloud:
         _showMessage('该云盘类型暂未开放');
     }
@@ -126,7 +128,8 @@ class _SourcesPageState extends ConsumerState<SourcesPage> {
         .where(
           (source) =>
               source.type == ResourceSourceType.emby ||
-              source.type == ResourceSourceType.jellyfin,
+              source.type == ResourceSourceType.jellyfin ||
+              source.type == ResourceSourceType.plex,
         )
         .toList(growable: false);
     final isMobile = PlatformCapabilities.isMobile;
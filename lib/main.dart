@@ -8,6 +8,7 @@ import 'package:flutter_skill/flutter_skill.dart';
 import 'package:window_manager/window_manager.dart';
 
 import 'app.dart';
+import 'core/platform/crash_log.dart';
 import 'core/platform/desktop_window.dart';
 import 'core/platform/platform_capabilities.dart';
 import 'core/platform/player_window.dart';
@@ -18,6 +19,7 @@ Future<void> main(List<String> args) async {
   if (kDebugMode) {
     FlutterSkillBinding.ensureInitialized();
   }
+  CrashLog.install();
 
   // Multi-window + window_manager are desktop-only. Calling them on iOS/Android
   // hangs the first frame (white screen) because those plugins have no mobile
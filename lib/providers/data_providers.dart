@@ -2,6 +2,7 @@ import 'package:flutter_riverpod/flutter_riverpod.dart';
 
 import 'package:http/http.dart' as http;
 
+import '../core/image/filmly_image_cache.dart';
 import '../data/database/database.dart';
 import '../data/models/app_config.dart';
 import '../data/models/continue_watching_item.dart';
@@ -14,11 +15,15 @@ import '../data/repositories/config_repository.dart';
 import '../data/repositories/episode_repository.dart';
 import '../data/repositories/media_repository.dart';
 import '../data/repositories/playback_progress_repository.dart';
+import '../data/repositories/scan_checkpoint_repository.dart';
+import '../services/library/extras_classifier.dart';
 import '../services/library/library_auto_scan_service.dart';
 import '../services/library/library_metadata_sync_service.dart';
 import '../services/library/library_scanner_service.dart';
+import '../services/library/media_library_entry_factory.dart';
 import '../services/metadata/intelligent_name_recognizer.dart';
 import '../services/metadata/tmdb_metadata_service.dart';
+import '../services/plex/plex_service.dart';
 
 /// App-lifetime drift database.
 final databaseProvider = Provider<AppDatabase>((ref) {
@@ -43,10 +48,35 @@ final episodeRepositoryProvider = Provider<EpisodeRepository>(
   (ref) => EpisodeRepository(ref.watch(databaseProvider)),
 );
 
+final scanCheckpointRepositoryProvider = Provider<ScanCheckpointRepository>(
+  (ref) => ScanCheckpointRepository(ref.watch(databaseProvider)),
+);
+
+/// Built-in extras rules plus the user's `extrasPatterns` from config.
+final extrasClassifierProvider = Provider<ExtrasClassifier>((ref) {
+  final config = ref.watch(configProvider).asData?.value;
+  return ExtrasClassifier.withPatterns(config?.extrasPatterns ?? const []);
+});
+
+/// Directory names scans prune: the user's `excludedDirectoryNames`, or the
+/// built-in NAS/OS housekeeping list when none are set.
+final excludedDirectoryNamesProvider = Provider<Set<String>>((ref) {
+  final config = ref.watch(configProvider).asData?.value;
+  final names = {
+    for (final name in config?.excludedDirectoryNames ?? const <String>[])
+      if (name.trim().isNotEmpty) name.trim().toLowerCase(),
+  };
+  return names.isEmpty
+      ? MediaLibraryEntryFactory.defaultExcludedDirectoryNames
+      : Set.unmodifiable(names);
+});
+
 final libraryScannerProvider = Provider<LibraryScannerService>(
   (ref) => LibraryScannerService(
     ref.watch(mediaRepositoryProvider),
     ref.watch(episodeRepositoryProvider),
+    ref.watch(excludedDirectoryNamesProvider),
+    ref.watch(extrasClassifierProvider),
   ),
 );
 
@@ -91,12 +121,37 @@ final configProvider = AsyncNotifierProvider<ConfigNotifier, AppConfig>(
 
 class ConfigNotifier extends AsyncNotifier<AppConfig> {
   @override
-  Future<AppConfig> build() => ref.watch(configRepositoryProvider).load();
+  Future<AppConfig> build() async {
+    final config = await ref.watch(configRepositoryProvider).load();
+    _applyImageCredentials(config);
+    return config;
+  }
 
   Future<void> save(AppConfig config) async {
     state = AsyncData(config);
+    _applyImageCredentials(config);
     await ref.read(configRepositoryProvider).save(config);
   }
+
+  /// The Plex base whose token is currently added to image URLs.
+  String? _plexImageBase;
+
+  /// Lets Plex posters load; their stored URLs don't carry the token. The
+  /// token is withdrawn from a server once its URL is changed or cleared.
+  void _applyImageCredentials(AppConfig config) {
+    final url = config.plexUrl.trim();
+    final base = url.isEmpty ? null : PlexService.normalizeBase(url);
+    final previous = _plexImageBase;
+    if (previous != null && previous != base) {
+      FilmlyImageCache.setOriginQuery(previous, const {});
+    }
+    _plexImageBase = base;
+    if (base == null) return;
+    final token = config.plexToken;
+    FilmlyImageCache.setOriginQuery(base, {
+      if (token.isNotEmpty) PlexService.tokenParameter: token,
+    });
+  }
 }
 
 /// Library items of a given type, sorted by title.
@@ -254,6 +309,37 @@ final episodeDetailsProvider =
           );
     });
 
+/// Identifies one season of one show for the aired-episode lookup.
+typedef SeasonRef = ({String showId, int season});
+
+/// How many episodes of a season TMDB lists as already aired, used to spot
+/// missing trailing episodes. Null without a TMDB id or API key.
+final airedEpisodeCountProvider = FutureProvider.family<int?, SeasonRef>((
+  ref,
+  key,
+) async {
+  final show = await ref.watch(mediaByIdProvider(key.showId).future);
+  final tmdbId = show?.tmdbId;
+  if (tmdbId == null) return null;
+
+  final config = await ref.watch(configProvider.future);
+  if (config.tmdbApiKey.isEmpty) return null;
+
+  final episodes = await ref
+      .watch(tmdbMetadataProvider)
+      .fetchSeasonEpisodes(
+        tvId: tmdbId,
+        seasonNumber: key.season,
+        apiKey: config.tmdbApiKey,
+      );
+  if (episodes.isEmpty) return null;
+  final today = DateTime.now();
+  return episodes.values.where((episode) {
+    final aired = DateTime.tryParse(episode.airDate);
+    return aired != null && !aired.isAfter(today);
+  }).length;
+});
+
 /// Top-billed cast for the detail page's 相关演员 row. Empty when the item
 /// lacks a TMDB id or no API key is configured.
 final castProvider = FutureProvider.family<List<TmdbCastMember>, String>((
@@ -3,11 +3,15 @@ import 'package:flutter_riverpod/flutter_riverpod.dart';
 import 'package:http/http.dart' as http;
 
 import 'data_providers.dart';
+import '../services/dlna/dlna_service.dart';
 import '../services/emby/emby_service.dart';
+import '../services/library/dlna_library_import_service.dart';
 import '../services/library/emby_library_import_service.dart';
+import '../services/library/plex_library_import_service.dart';
 import '../services/library/smb_library_import_service.dart';
 import '../services/library/webdav_library_import_service.dart';
 import '../services/playback/playback_source_resolver.dart';
+import '../services/plex/plex_service.dart';
 import '../services/smb/smb_proxy_server.dart';
 import '../services/smb/smb_service.dart';
 import '../services/webdav/webdav_service.dart';
@@ -40,12 +44,29 @@ final embyServiceProvider = Provider<EmbyService>((ref) {
   return EmbyService(client);
 });
 
+/// App-lifetime Plex session, shared by the import flow and resolver.
+final plexServiceProvider = Provider<PlexService>((ref) {
+  final client = http.Client();
+  ref.onDispose(client.close);
+  return PlexService(client);
+});
+
+/// App-lifetime DLNA client for discovering and browsing UPnP media servers.
+final dlnaServiceProvider = Provider<DlnaService>((ref) {
+  final client = http.Client();
+  ref.onDispose(client.close);
+  return DlnaService(client);
+});
+
 /// Imports the currently browsed SMB folder into the library database.
 final smbLibraryImportProvider = Provider<SmbLibraryImportService>((ref) {
   return SmbLibraryImportService(
     ref.watch(smbServiceProvider),
     ref.watch(mediaRepositoryProvider),
     ref.watch(episodeRepositoryProvider),
+    ref.watch(excludedDirectoryNamesProvider),
+    ref.watch(scanCheckpointRepositoryProvider),
+    ref.watch(extrasClassifierProvider),
   );
 });
 
@@ -55,6 +76,8 @@ final webDavLibraryImportProvider = Provider<WebDavLibraryImportService>((ref) {
     ref.watch(webDavServiceProvider),
     ref.watch(mediaRepositoryProvider),
     ref.watch(episodeRepositoryProvider),
+    ref.watch(excludedDirectoryNamesProvider),
+    ref.watch(extrasClassifierProvider),
   );
 });
 
@@ -67,12 +90,33 @@ final embyLibraryImportProvider = Provider<EmbyLibraryImportService>((ref) {
   );
 });
 
-/// Resolves local, SMB, WebDAV, or Emby items into player-ready sources.
+/// Imports a Plex library into the database.
+final plexLibraryImportProvider = Provider<PlexLibraryImportService>((ref) {
+  return PlexLibraryImportService(
+    ref.watch(plexServiceProvider),
+    ref.watch(mediaRepositoryProvider),
+    ref.watch(episodeRepositoryProvider),
+  );
+});
+
+/// Imports a browsed DLNA container into the database.
+final dlnaLibraryImportProvider = Provider<DlnaLibraryImportService>((ref) {
+  return DlnaLibraryImportService(
+    ref.watch(dlnaServiceProvider),
+    ref.watch(mediaRepositoryProvider),
+    ref.watch(episodeRepositoryProvider),
+    ref.watch(extrasClassifierProvider),
+    ref.watch(excludedDirectoryNamesProvider),
+  );
+});
+
+/// Resolves local, SMB, WebDAV, Emby, or Plex items into player-ready sources.
 final playbackSourceResolverProvider = Provider<PlaybackSourceResolver>((ref) {
   return PlaybackSourceResolver(
     ref.watch(smbServiceProvider),
     ref.watch(smbProxyProvider),
     emby: ref.watch(embyServiceProvider),
+    plex: ref.watch(plexServiceProvider),
     webDav: ref.watch(webDavServiceProvider),
     smbConfig: () {
       final config = ref.read(configProvider).asData?.value;
@@ -102,5 +146,10 @@ final playbackSourceResolverProvider = Provider<PlaybackSourceResolver>((ref) {
         password: config.embyPassword,
       );
     },
+    plexConfig: () {
+      final config = ref.read(configProvider).asData?.value;
+      if (config == null || config.plexUrl.isEmpty) return null;
+      return PlexConfig(url: config.plexUrl, token: config.plexToken);
+    },
   );
 });
@@ -85,11 +85,39 @@ class DatabaseTransferService {
     if (!await file.exists()) {
       throw StateError('迁移文件不存在：$path');
     }
-    final source = AppDatabase(NativeDatabase(file, enableMigrations: false));
+    final version = await _schemaVersionOf(file);
+    if (version > _target.schemaVersion) {
+      throw StateError('迁移文件来自更新版本的 Open Filmly（数据库版本 $version），请先升级当前设备的应用');
+    }
+
+    // Older snapshots are upgraded on a scratch copy so the user's file stays
+    // untouched. Version 0 means no drift history (e.g. an Electron media.db),
+    // which is read as-is like before.
+    final needsMigration = version > 0 && version < _target.schemaVersion;
+    final scratch = needsMigration
+        ? await Directory.systemTemp.createTemp('open_filmly-import')
+        : null;
+    final readable = scratch == null
+        ? file
+        : await file.copy('${scratch.path}/import.sqlite');
+    final source = AppDatabase(
+      NativeDatabase(readable, enableMigrations: needsMigration),
+    );
     try {
       return await mergeFrom(source);
     } finally {
       await source.close();
+      await scratch?.delete(recursive: true);
+    }
+  }
+
+  Future<int> _schemaVersionOf(File file) async {
+    final probe = AppDatabase(NativeDatabase(file, enableMigrations: false));
+    try {
+      final row = await probe.customSelect('PRAGMA user_version').getSingle();
+      return row.read<int>('user_version');
+    } finally {
+      await probe.close();
     }
   }
 
@@ -132,6 +160,20 @@ class DatabaseTransferService {
     return temporary.rename(path);
   }
 
+  /// Drops episodes whose parent show no longer exists and runs `VACUUM` so a
+  /// long-lived library file gives back the space freed by rescans. Returns
+  /// the number of orphaned episode rows removed.
+  Future<int> compact() async {
+    final removed = await _target.customUpdate(
+      'DELETE FROM episodes WHERE show_id NOT IN (SELECT id FROM media)',
+      updates: {_target.episodes},
+      updateKind: UpdateKind.delete,
+    );
+    // VACUUM can't run inside a transaction, so it goes straight through.
+    await _target.customStatement('VACUUM');
+    return removed;
+  }
+
   String _mergeConfigValue(String key, String incoming, String? current) {
     if (current == null || !key.startsWith(_playbackPrefix)) {
       return incoming;
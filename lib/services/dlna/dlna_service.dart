@@ -0,0 +1,264 @@
+import 'dart:async';
+import 'dart:convert';
+import 'dart:io';
+
+import 'package:http/http.dart' as http;
+
+/// A UPnP MediaServer found on the LAN via SSDP.
+class DlnaServer {
+  const DlnaServer({
+    required this.name,
+    required this.location,
+    required this.controlUrl,
+  });
+
+  /// The device's `friendlyName`, e.g. `Synology Media Server`.
+  final String name;
+
+  /// URL of the device description document (the SSDP `LOCATION`).
+  final Uri location;
+
+  /// ContentDirectory control endpoint used for SOAP `Browse` calls.
+  final Uri controlUrl;
+}
+
+/// One DIDL-Lite object returned by a ContentDirectory `Browse`.
+class DlnaEntry {
+  const DlnaEntry({
+    required this.id,
+    required this.title,
+    required this.isContainer,
+    this.resourceUrl,
+    this.size,
+    this.upnpClass = '',
+  });
+
+  final String id;
+  final String title;
+  final bool isContainer;
+
+  /// The `upnp:class`, e.g. `object.item.videoItem.movie`.
+  final String upnpClass;
+
+  /// Whether the item can go in the video library. Servers that omit the
+  /// class get the benefit of the doubt.
+  bool get isVideo =>
+      !isContainer &&
+      (upnpClass.isEmpty || upnpClass.startsWith('object.item.videoItem'));
+
+  /// Direct HTTP URL of the media (`<res>`); null for containers.
+  final Uri? resourceUrl;
+  final int? size;
+}
+
+/// Minimal DLNA client: SSDP discovery of MediaServers plus ContentDirectory
+/// browsing, so media behind a DLNA server (rather than a file share) can be
+/// listed and played over plain HTTP.
+///
+/// The XML is parsed with targeted regexes: the payloads are small, flat, and
+/// machine-generated, which keeps this free of an XML dependency.
+class DlnaService {
+  DlnaService(this._client);
+
+  final http.Client _client;
+
+  static const _ssdpAddress = '239.255.255.250';
+  static const _ssdpPort = 1900;
+  static const _mediaServerType = 'urn:schemas-upnp-org:device:MediaServer:1';
+  static const _contentDirectoryType =
+      'urn:schemas-upnp-org:service:ContentDirectory:1';
+
+  /// How long a device description or Browse call may take; a server that
+  /// stops answering must not hang discovery or the browser.
+  static const _requestTimeout = Duration(seconds: 10);
+
+  /// Broadcasts an SSDP M-SEARCH and resolves every responding MediaServer.
+  /// Servers whose description can't be fetched in time or lack a
+  /// ContentDirectory are skipped.
+  Future<List<DlnaServer>> discover({
+    Duration timeout = const Duration(seconds: 3),
+  }) async {
+    final socket = await RawDatagramSocket.bind(InternetAddress.anyIPv4, 0);
+    final locations = <Uri>{};
+    try {
+      final done = Completer<void>();
+      final subscription = socket.listen((event) {
+        if (event != RawSocketEvent.read) return;
+        final datagram = socket.receive();
+        if (datagram == null) return;
+        final location = parseSsdpLocation(
+          utf8.decode(datagram.data, allowMalformed: true),
+        );
+        if (location != null) locations.add(location);
+      });
+
+      final search = [
+        'M-SEARCH * HTTP/1.1',
+        'HOST: $_ssdpAddress:$_ssdpPort',
+        'MAN: "ssdp:discover"',
+        'MX: ${timeout.inSeconds.clamp(1, 5)}',
+        'ST: $_mediaServerType',
+        '',
+        '',
+      ].join('\r\n');
+      socket.send(
+        utf8.encode(search),
+        InternetAddress(_ssdpAddress),
+        _ssdpPort,
+      );
+
+      Timer(timeout, done.complete);
+      await done.future;
+      await subscription.cancel();
+    } finally {
+      socket.close();
+    }
+
+    final servers = <DlnaServer>[];
+    for (final location in locations) {
+      try {
+        final response = await _client.get(location).timeout(_requestTimeout);
+        if (response.statusCode != 200) continue;
+        final server = parseDescription(response.body, location);
+        if (server != null) servers.add(server);
+      } catch (_) {
+        // Unreachable, unresponsive or malformed device — skip it.
+      }
+    }
+    return servers;
+  }
+
+  /// Lists the direct children of [objectId] (`0` is the root container).
+  Future<List<DlnaEntry>> browse(
+    DlnaServer server, {
+    String objectId = '0',
+  }) async {
+    final body =
+        '<?xml version="1.0" encoding="utf-8"?>'
+        '<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" '
+        's:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">'
+        '<s:Body><u:Browse xmlns:u="$_contentDirectoryType">'
+        '<ObjectID>${_escape(objectId)}</ObjectID>'
+        '<BrowseFlag>BrowseDirectChildren</BrowseFlag>'
+        '<Filter>*</Filter>'
+        '<StartingIndex>0</StartingIndex>'
+        '<RequestedCount>0</RequestedCount>'
+        '<SortCriteria></SortCriteria>'
+        '</u:Browse></s:Body></s:Envelope>';
+    final response = await _client
+        .post(
+          server.controlUrl,
+          headers: {
+            'Content-Type': 'text/xml; charset="utf-8"',
+            'SOAPAction': '"$_contentDirectoryType#Browse"',
+          },
+          body: body,
+        )
+        .timeout(
+          _requestTimeout,
+          onTimeout: () => throw StateError('DLNA 服务器无响应'),
+        );
+    if (response.statusCode != 200) {
+      throw StateError('DLNA 浏览失败（HTTP ${response.statusCode}）');
+    }
+    return parseBrowseResponse(utf8.decode(response.bodyBytes));
+  }
+
+  /// Extracts the `LOCATION` header from an SSDP response datagram.
+  static Uri? parseSsdpLocation(String response) {
+    for (final line in const LineSplitter().convert(response)) {
+      final colon = line.indexOf(':');
+      if (colon <= 0) continue;
+      if (line.substring(0, colon).trim().toLowerCase() != 'location') {
+        continue;
+      }
+      return Uri.tryParse(line.substring(colon + 1).trim());
+    }
+    return null;
+  }
+
+  /// Builds a [DlnaServer] from a device description, or null when the device
+  /// doesn't expose a ContentDirectory service.
+  static DlnaServer? parseDescription(String xml, Uri location) {
+    final name = _tagText(xml, 'friendlyName') ?? location.host;
+    for (final match in RegExp(
+      r'<service>(.*?)</service>',
+      dotAll: true,
+    ).allMatches(xml)) {
+      final service = match.group(1)!;
+      final type = _tagText(service, 'serviceType') ?? '';
+      if (!type.startsWith('urn:schemas-upnp-org:service:ContentDirectory:')) {
+        continue;
+      }
+      final control = _tagText(service, 'controlURL');
+      if (control == null || control.isEmpty) return null;
+      final base = _tagText(xml, 'URLBase');
+      final root = base == null || base.isEmpty ? location : Uri.parse(base);
+      return DlnaServer(
+        name: name,
+        location: location,
+        controlUrl: root.resolve(control),
+      );
+    }
+    return null;
+  }
+
+  /// Parses the DIDL-Lite payload embedded (escaped) in a `BrowseResponse`.
+  static List<DlnaEntry> parseBrowseResponse(String soap) {
+    final result = _tagText(soap, 'Result');
+    if (result == null || result.isEmpty) return const [];
+    final didl = _unescape(result);
+
+    final entries = <DlnaEntry>[];
+    for (final match in RegExp(
+      r'<(container|item)\b([^>]*)>(.*?)</\1>',
+      dotAll: true,
+    ).allMatches(didl)) {
+      final isContainer = match.group(1) == 'container';
+      final attributes = match.group(2)!;
+      final inner = match.group(3)!;
+      final id = RegExp(r'\bid="([^"]*)"').firstMatch(attributes)?.group(1);
+      if (id == null) continue;
+
+      final res = RegExp(r'<res\b([^>]*)>([^<]*)</res>').firstMatch(inner);
+      final sizeText = res == null
+          ? null
+          : RegExp(r'\bsize="(\d+)"').firstMatch(res.group(1)!)?.group(1);
+      entries.add(
+        DlnaEntry(
+          id: _unescape(id),
+          title: _unescape(_tagText(inner, 'dc:title') ?? id),
+          isContainer: isContainer,
+          resourceUrl: isContainer || res == null
+              ? null
+              : Uri.tryParse(_unescape(res.group(2)!.trim())),
+          size: sizeText == null ? null : int.parse(sizeText),
+          upnpClass: _tagText(inner, 'upnp:class') ?? '',
+        ),
+      );
+    }
+    return entries;
+  }
+
+  static String? _tagText(String xml, String tag) {
+    final match = RegExp(
+      '<(?:[\\w-]+:)?${RegExp.escape(tag.split(':').last)}\\b[^>]*>'
+      '(.*?)'
+      '</(?:[\\w-]+:)?${RegExp.escape(tag.split(':').last)}>',
+      dotAll: true,
+    ).firstMatch(xml);
+    return match?.group(1)?.trim();
+  }
+
+  static String _escape(String text) => text
+      .replaceAll('&', '&amp;')
+      .replaceAll('<', '&lt;')
+      .replaceAll('>', '&gt;');
+
+  static String _unescape(String text) => text
+      .replaceAll('&lt;', '<')
+      .replaceAll('&gt;', '>')
+      .replaceAll('&quot;', '"')
+      .replaceAll('&apos;', "'")
+      .replaceAll('&amp;', '&');
+}
@@ -0,0 +1,131 @@
+import '../../data/models/media.dart';
+import '../../data/repositories/episode_repository.dart';
+import '../../data/repositories/media_repository.dart';
+import '../dlna/dlna_service.dart';
+import 'extras_classifier.dart';
+import 'media_library_entry_factory.dart';
+
+/// Summary returned after importing a DLNA container into the library.
+class DlnaImportResult {
+  const DlnaImportResult({
+    required this.scannedItems,
+    required this.importedItems,
+    required this.movieCount,
+    required this.tvCount,
+    required this.episodeCount,
+    required this.mediaIds,
+  });
+
+  final int scannedItems;
+  final int importedItems;
+  final int movieCount;
+  final int tvCount;
+  final int episodeCount;
+  final List<String> mediaIds;
+}
+
+/// Recursively imports the video items under a DLNA container. Items keep
+/// their HTTP resource URL as the playable path; the MediaServer streams them
+/// without a login.
+class DlnaLibraryImportService {
+  DlnaLibraryImportService(
+    this._dlna,
+    this._repo, [
+    this._episodeRepo,
+    this.extras = const ExtrasClassifier(),
+    this.excludedDirectoryNames =
+        MediaLibraryEntryFactory.defaultExcludedDirectoryNames,
+  ]);
+
+  final DlnaService _dlna;
+  final MediaRepository _repo;
+  final EpisodeRepository? _episodeRepo;
+
+  /// Decides which samples/trailers/featurettes are left out of the import.
+  final ExtrasClassifier extras;
+
+  /// Lowercase container names whose items are left out (recycle bins, ...).
+  final Set<String> excludedDirectoryNames;
+
+  /// Imports everything below [objectId]. [titlePath] is the container titles
+  /// leading to it (`/Video/Movies`), which the title parser uses the same way
+  /// as folder names.
+  Future<DlnaImportResult> importContainer(
+    DlnaServer server, {
+    String objectId = '0',
+    String titlePath = '',
+  }) async {
+    var scannedItems = 0;
+    var importedItems = 0;
+    var movieCount = 0;
+    var tvCount = 0;
+    var episodeCount = 0;
+    final mediaIds = <String>[];
+    final scannedShows = <String, Media>{};
+    // Some servers list the same container under several views.
+    final visited = <String>{};
+
+    Future<void> walk(String id, String parentPath) async {
+      if (!visited.add(id)) return;
+      for (final entry in await _dlna.browse(server, objectId: id)) {
+        final entryPath = '$parentPath/${entry.title.replaceAll('/', ' ')}';
+        if (entry.isContainer) {
+          await walk(entry.id, entryPath);
+          continue;
+        }
+        final resourceUrl = entry.resourceUrl;
+        if (!entry.isVideo ||
+            resourceUrl == null ||
+            MediaLibraryEntryFactory.isJunkPath(
+              entryPath,
+              extras,
+              excludedDirectoryNames,
+              titlePath,
+            )) {
+          continue;
+        }
+
+        scannedItems++;
+        final libraryEntry = MediaLibraryEntryFactory.fromDlnaItem(
+          server: server.location.toString(),
+          titlePath: entryPath,
+          resourceUrl: resourceUrl,
+        );
+        await _repo.upsertScanned(libraryEntry.media);
+        importedItems++;
+        mediaIds.add(libraryEntry.media.id);
+
+        if (libraryEntry.hasEpisode && _episodeRepo != null) {
+          await _episodeRepo.upsert(libraryEntry.episode!);
+          episodeCount++;
+        }
+
+        switch (libraryEntry.media.type) {
+          case MediaType.movie:
+            movieCount++;
+            break;
+          case MediaType.tv:
+            tvCount++;
+            scannedShows[libraryEntry.media.id] = libraryEntry.media;
+            break;
+          case MediaType.unknown:
+            break;
+        }
+      }
+    }
+
+    await walk(objectId, titlePath);
+    for (final show in scannedShows.values) {
+      await _repo.consolidateTvShow(show);
+    }
+
+    return DlnaImportResult(
+      scannedItems: scannedItems,
+      importedItems: importedItems,
+      movieCount: movieCount,
+      tvCount: tvCount,
+      episodeCount: episodeCount,
+      mediaIds: mediaIds,
+    );
+  }
+}
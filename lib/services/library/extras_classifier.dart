@@ -0,0 +1,179 @@
+import 'package:path/path.dart' as path;
+
+/// Bonus material that ships beside a feature but isn't a library item.
+enum ExtraKind {
+  sample,
+  trailer,
+  featurette,
+  behindTheScenes,
+  deletedScene,
+  interview,
+  other,
+}
+
+/// Why [ExtrasClassifier] tagged a file, so a mislabel can be traced back to
+/// the rule that caused it.
+class ExtraMatch {
+  const ExtraMatch({
+    required this.kind,
+    required this.confidence,
+    required this.rule,
+  });
+
+  final ExtraKind kind;
+
+  /// 0–1. Files at or above [ExtrasClassifier.skipThreshold] are not imported;
+  /// weaker matches are imported like any other video.
+  final double confidence;
+
+  /// Name of the rule that fired, e.g. `plex-suffix` or `custom:<pattern>`.
+  final String rule;
+}
+
+class _ExtrasRule {
+  const _ExtrasRule(
+    this.name,
+    this.pattern,
+    this.confidence,
+    this.kindOf, [
+    this.unless,
+  ]);
+
+  final String name;
+  final RegExp pattern;
+  final double confidence;
+  final ExtraKind Function(Match match) kindOf;
+
+  /// Paths that match [pattern] but are not extras after all.
+  final RegExp? unless;
+}
+
+/// Tags samples, trailers, featurettes and other extras by file and folder
+/// name. Each rule carries a confidence: exact conventions (`sample.mkv`,
+/// Plex's `-trailer` suffix, a `Featurettes/` folder) are trusted enough to
+/// skip the file, while a stray "trailer" word in a title scores below
+/// [skipThreshold] and is still imported. Only [shouldSkip] feeds the scans;
+/// [classify] exposes the kind, confidence and rule behind that decision.
+///
+/// Users add their own regexes via `AppConfig.extrasPatterns`; they are
+/// matched case-insensitively against the `/`-separated path (below the
+/// scanned root when the walker passes one) and always skip.
+class ExtrasClassifier {
+  const ExtrasClassifier([this._custom = const []]);
+
+  /// Compiles [patterns], ignoring blank and invalid ones so one typo in the
+  /// config doesn't break every scan.
+  factory ExtrasClassifier.withPatterns(List<String> patterns) {
+    final custom = <_ExtrasRule>[];
+    for (final source in patterns) {
+      if (source.trim().isEmpty) continue;
+      try {
+        custom.add(
+          _ExtrasRule(
+            'custom:$source',
+            RegExp(source, caseSensitive: false),
+            1,
+            (_) => ExtraKind.other,
+          ),
+        );
+      } on FormatException {
+        continue;
+      }
+    }
+    return ExtrasClassifier(List.unmodifiable(custom));
+  }
+
+  static const skipThreshold = 0.8;
+
+  final List<_ExtrasRule> _custom;
+
+  static final _builtIn = <_ExtrasRule>[
+    _ExtrasRule(
+      'sample-name',
+      RegExp(r'(?:^|/)sample$|[-.]sample$'),
+      0.95,
+      (_) => ExtraKind.sample,
+    ),
+    _ExtrasRule(
+      'trailer-name',
+      RegExp(r'(?:^|/)trailer$'),
+      0.95,
+      (_) => ExtraKind.trailer,
+    ),
+    // Plex/Jellyfin local-extras suffixes: `Dune (2021)-featurette.mkv`.
+    _ExtrasRule(
+      'plex-suffix',
+      RegExp(r'-(trailer|featurette|behindthescenes|deleted|interview)$'),
+      0.9,
+      (match) => _kindFor(match.group(1)!),
+    ),
+    _ExtrasRule(
+      'extras-folder',
+      RegExp(
+        r'/(samples?|trailers|featurettes|behind the scenes|deleted scenes|'
+        r'interviews|extras|花絮|特典)/[^/]+$',
+      ),
+      0.85,
+      (match) => _kindFor(match.group(1)!),
+      // Extras folders hold clips, not numbered episodes: an `S01E01` right
+      // under `/Extras/` is an episode of a show with that name.
+      RegExp(r'\bs\d{1,2}\s*e\d{1,3}\b|\b\d{1,2}x\d{2,3}\b|第\s*\d+\s*[集话]'),
+    ),
+    _ExtrasRule(
+      'trailer-word',
+      RegExp(r'(?:\btrailer\b|预告)[^/]*$'),
+      0.6,
+      (_) => ExtraKind.trailer,
+    ),
+    _ExtrasRule(
+      'sample-word',
+      RegExp(r'\bsample\b[^/]*$'),
+      0.6,
+      (_) => ExtraKind.sample,
+    ),
+  ];
+
+  /// The strongest match for [filePath], or null for an ordinary video.
+  ExtraMatch? classify(String filePath) {
+    final normalized = filePath.replaceAll('\\', '/');
+    for (final rule in _custom) {
+      if (rule.pattern.hasMatch(normalized)) {
+        return ExtraMatch(
+          kind: ExtraKind.other,
+          confidence: rule.confidence,
+          rule: rule.name,
+        );
+      }
+    }
+
+    final subject = path.posix.withoutExtension(normalized).toLowerCase();
+    for (final rule in _builtIn) {
+      final match = rule.pattern.firstMatch(subject);
+      if (match == null || (rule.unless?.hasMatch(subject) ?? false)) {
+        continue;
+      }
+      // Rules are ordered by confidence, so the first hit is the strongest.
+      return ExtraMatch(
+        kind: rule.kindOf(match),
+        confidence: rule.confidence,
+        rule: rule.name,
+      );
+    }
+    return null;
+  }
+
+  /// Whether [filePath] is confidently an extra and should stay out of the
+  /// library.
+  bool shouldSkip(String filePath) =>
+      (classify(filePath)?.confidence ?? 0) >= skipThreshold;
+
+  static ExtraKind _kindFor(String label) => switch (label) {
+    'sample' || 'samples' => ExtraKind.sample,
+    'trailer' || 'trailers' => ExtraKind.trailer,
+    'featurette' || 'featurettes' || '花絮' => ExtraKind.featurette,
+    'behindthescenes' || 'behind the scenes' => ExtraKind.behindTheScenes,
+    'deleted' || 'deleted scenes' => ExtraKind.deletedScene,
+    'interview' || 'interviews' => ExtraKind.interview,
+    _ => ExtraKind.other,
+  };
+}
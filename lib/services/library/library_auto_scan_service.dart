@@ -60,8 +60,8 @@ class LibraryAutoScanService {
     // Hygiene pass first: drop OS-junk rows (macOS `._` sidecars etc.) and
     // re-derive dirty titles. Runs even when no local folders are set, so it
     // also cleans SMB/WebDAV imports.
-    final purged = await _purgeJunk();
-    final fakeEps = await _purgeFakeEpisodes();
+    final purged = await _purgeJunk(config.selectedFolders);
+    final fakeEps = await _purgeFakeEpisodes(config.selectedFolders);
     final repairedEpisodes = await _repairEpisodeNumbers();
     final duplicateEpisodes = await _removeExactEpisodeDuplicates();
     final retitled = await _retitleDirtyTitles();
@@ -120,14 +120,48 @@ class LibraryAutoScanService {
     );
   }
 
+  /// Whether [path] is junk by the same extras rules and excluded folders the
+  /// scanner applies, judged below the root it was scanned from: the
+  /// configured folder for local files. The folder an SMB/WebDAV/DLNA import
+  /// started from isn't stored, so remote rows are judged below their share
+  /// or server.
+  bool _isJunk(String path, List<String> folders) {
+    return MediaLibraryEntryFactory.isJunkPath(
+      path,
+      _scanner.extras,
+      _scanner.excludedDirectoryNames,
+      _scanRootOf(path, folders),
+    );
+  }
+
+  static String _scanRootOf(String path, List<String> folders) {
+    final normalized = path.replaceAll('\\', '/');
+    var root = '';
+    for (final folder in folders) {
+      final candidate = folder.trim().replaceAll('\\', '/');
+      if (candidate.length > root.length &&
+          MediaLibraryEntryFactory.pathBelowRoot(normalized, candidate) !=
+              normalized) {
+        root = candidate;
+      }
+    }
+    if (root.isNotEmpty) return root;
+    final uri = Uri.tryParse(normalized);
+    if (uri == null || !uri.hasAuthority) return '';
+    final server = '${uri.scheme}://${uri.authority}';
+    if (uri.scheme != 'smb' || uri.pathSegments.isEmpty) return server;
+    // Everything on a share is a candidate, so the share name itself isn't.
+    return '$server/${uri.pathSegments.first}';
+  }
+
   /// Removes rows whose source path is OS junk (e.g. macOS `._` AppleDouble
   /// sidecars) that earlier scans imported as phantom duplicates.
-  Future<int> _purgeJunk() async {
+  Future<int> _purgeJunk(List<String> folders) async {
     final items = await _repo.browse(deduplicateShows: false);
     var purged = 0;
     for (final media in items) {
       final path = media.fullPath ?? media.path;
-      if (path.isNotEmpty && MediaLibraryEntryFactory.isJunkPath(path)) {
+      if (path.isNotEmpty && _isJunk(path, folders)) {
         await _repo.deleteById(media.id);
         purged++;
       }
@@ -138,7 +172,7 @@ class LibraryAutoScanService {
   /// Drops placeholder episodes whose path is a media id (`tv:smb:…`) rather
   /// than a real video file — leftovers from older default-episode injection
   /// and show consolidation.
-  Future<int> _purgeFakeEpisodes() async {
+  Future<int> _purgeFakeEpisodes(List<String> folders) async {
     final episodeRepo = _episodeRepo;
     if (episodeRepo == null) return 0;
     final shows = await _repo.browse(
@@ -152,8 +186,8 @@ class LibraryAutoScanService {
         final path = (episode.path).trim();
         final full = (episode.fullPath ?? '').trim();
         final looksFake =
-            MediaLibraryEntryFactory.isJunkPath(path) ||
-            (full.isNotEmpty && MediaLibraryEntryFactory.isJunkPath(full)) ||
+            _isJunk(path, folders) ||
+            (full.isNotEmpty && _isJunk(full, folders)) ||
             path.startsWith('tv:') ||
             full.startsWith('tv:') ||
             (!MediaLibraryEntryFactory.isVideoPath(path) &&
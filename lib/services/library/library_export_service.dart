@@ -0,0 +1,171 @@
+import 'dart:io';
+
+import '../../data/models/media.dart';
+import '../../data/repositories/episode_repository.dart';
+import '../../data/repositories/media_repository.dart';
+
+/// One playable file in an exported library listing.
+class LibraryExportRow {
+  const LibraryExportRow({
+    required this.title,
+    required this.year,
+    required this.type,
+    required this.location,
+    this.seasonNumber,
+    this.episodeNumber,
+    this.episodeTitle = '',
+  });
+
+  final String title;
+  final String year;
+  final MediaType type;
+
+  /// `smb://`, `http(s)://`, or local path a third-party player can open.
+  final String location;
+  final int? seasonNumber;
+  final int? episodeNumber;
+  final String episodeTitle;
+
+  String get displayTitle {
+    final season = seasonNumber;
+    final episode = episodeNumber;
+    if (season == null || episode == null) {
+      return year.isEmpty ? title : '$title ($year)';
+    }
+    final code =
+        'S${season.toString().padLeft(2, '0')}'
+        'E${episode.toString().padLeft(2, '0')}';
+    return episodeTitle.isEmpty
+        ? '$title $code'
+        : '$title $code - $episodeTitle';
+  }
+}
+
+/// Exports the library as an M3U playlist or a CSV sheet, so it can be opened
+/// in VLC/mpv or a spreadsheet outside the app.
+///
+/// Emby and Plex items are skipped: they are stored by server item id and only
+/// become playable once the app attaches a live access token.
+class LibraryExportService {
+  LibraryExportService(this._media, this._episodes);
+
+  final MediaRepository _media;
+  final EpisodeRepository _episodes;
+
+  Future<List<LibraryExportRow>> collect() async {
+    final rows = <LibraryExportRow>[];
+    final items = await _media.browse(deduplicateShows: false);
+    for (final media in items) {
+      if (media.type == MediaType.tv) {
+        for (final episode in await _episodes.getByShow(media.id)) {
+          final location = locationFor(episode.path);
+          if (location == null) continue;
+          rows.add(
+            LibraryExportRow(
+              title: media.title,
+              year: media.year,
+              type: MediaType.tv,
+              location: location,
+              seasonNumber: episode.seasonNumber,
+              episodeNumber: episode.episodeNumber,
+              episodeTitle: episode.title,
+            ),
+          );
+        }
+        continue;
+      }
+      final location = locationFor(media.path);
+      if (location == null) continue;
+      rows.add(
+        LibraryExportRow(
+          title: media.title,
+          year: media.year,
+          type: media.type,
+          location: location,
+        ),
+      );
+    }
+    return rows;
+  }
+
+  /// Writes [collect]'s rows to [path]; `.csv` selects CSV, anything else M3U.
+  Future<File> exportToPath(String path) async {
+    final rows = await collect();
+    final content = path.toLowerCase().endsWith('.csv')
+        ? toCsv(rows)
+        : toM3u(rows);
+    return File(path).writeAsString(content);
+  }
+
+  static String toM3u(List<LibraryExportRow> rows) {
+    final buffer = StringBuffer('#EXTM3U\n');
+    for (final row in rows) {
+      buffer
+        ..writeln('#EXTINF:-1,${row.displayTitle.replaceAll('\n', ' ')}')
+        ..writeln(row.location);
+    }
+    return buffer.toString();
+  }
+
+  static String toCsv(List<LibraryExportRow> rows) {
+    final buffer = StringBuffer()
+      ..writeln('title,year,type,season,episode,episode_title,location');
+    for (final row in rows) {
+      buffer.writeln(
+        [
+          row.title,
+          row.year,
+          row.type.value,
+          row.seasonNumber?.toString() ?? '',
+          row.episodeNumber?.toString() ?? '',
+          row.episodeTitle,
+          row.location,
+        ].map(_csvField).join(','),
+      );
+    }
+    return buffer.toString();
+  }
+
+  /// Maps a stored media/episode path to something an external player can
+  /// open, or null when the path is only meaningful inside the app.
+  static String? locationFor(String storedPath) {
+    if (storedPath.startsWith('webdav|')) {
+      // `webdav|<base>|<relative path>`, see MediaLibraryEntryFactory. The
+      // base is the URL the user entered; only the file path is raw.
+      final parts = storedPath.split('|');
+      if (parts.length < 3) return null;
+      final base = parts[1].replaceAll(RegExp(r'/+$'), '');
+      final relative = parts.sublist(2).join('|');
+      return '$base/${_encodeSegments(relative)}';
+    }
+    final smb = RegExp(r'^smb://[^/]*', caseSensitive: false);
+    final authority = smb.firstMatch(storedPath);
+    if (authority != null) {
+      // Stored SMB URLs carry file names verbatim.
+      final rest = storedPath.substring(authority.end);
+      return '${authority[0]}/${_encodeSegments(rest)}';
+    }
+    // HTTP paths (DLNA resources) are stored as already-encoded URLs.
+    if (RegExp(r'^https?://', caseSensitive: false).hasMatch(storedPath)) {
+      return storedPath;
+    }
+    if (storedPath.startsWith('/') ||
+        RegExp(r'^[A-Za-z]:[\\/]').hasMatch(storedPath)) {
+      return storedPath;
+    }
+    return null;
+  }
+
+  /// Percent-encodes each segment of a raw `/`-separated path so `#`, `?`
+  /// and `%` in file names stay part of the path.
+  static String _encodeSegments(String rawPath) => rawPath
+      .split('/')
+      .where((segment) => segment.isNotEmpty)
+      .map(Uri.encodeComponent)
+      .join('/');
+
+  static String _csvField(String value) {
+    if (!value.contains(RegExp(r'[",\r\n]'))) return value;
+    return '"${value.replaceAll('"', '""')}"';
+  }
+}
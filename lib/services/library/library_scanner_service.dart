@@ -1,8 +1,11 @@
 import 'dart:io';
 
+import 'package:path/path.dart' as path;
+
 import '../../data/models/media.dart';
 import '../../data/repositories/episode_repository.dart';
 import '../../data/repositories/media_repository.dart';
+import 'extras_classifier.dart';
 import 'media_library_entry_factory.dart';
 
 /// Summary returned after scanning one or more configured folders.
@@ -31,11 +34,24 @@ class LibraryScanResult {
 /// TV shows are split into a parent show entry (in the media table) plus
 /// individual episodes (in the episodes table).
 class LibraryScannerService {
-  LibraryScannerService(this._repo, [this._episodeRepo]);
+  LibraryScannerService(
+    this._repo, [
+    this._episodeRepo,
+    this.excludedDirectoryNames =
+        MediaLibraryEntryFactory.defaultExcludedDirectoryNames,
+    this.extras = const ExtrasClassifier(),
+  ]);
 
   final MediaRepository _repo;
   final EpisodeRepository? _episodeRepo;
 
+  /// Lowercase directory names pruned during the walk (NAS thumbnails,
+  /// recycle bins, ...).
+  final Set<String> excludedDirectoryNames;
+
+  /// Decides which samples/trailers/featurettes are left out of the import.
+  final ExtrasClassifier extras;
+
   Future<LibraryScanResult> scanFolders(List<String> folders) async {
     final roots = folders
         .map((folder) => folder.trim())
@@ -51,19 +67,35 @@ class LibraryScannerService {
     final mediaIds = <String>[];
     final scannedShows = <String, Media>{};
 
-    for (final root in roots) {
-      final dir = Directory(root);
-      if (!await dir.exists()) {
-        missingFolders++;
-        continue;
-      }
-
-      await for (final entity in dir.list(
-        recursive: true,
-        followLinks: false,
-      )) {
+    Future<void> walk(Directory dir, String root) async {
+      final entities = await dir.list(followLinks: false).toList();
+      final siblingNames = {
+        for (final entity in entities)
+          if (MediaLibraryEntryFactory.isDownloadMarker(entity.path))
+            path.basename(entity.path).toLowerCase(),
+      };
+      for (final entity in entities) {
+        if (entity is Directory) {
+          if (MediaLibraryEntryFactory.isExcludedDirectoryName(
+            path.basename(entity.path),
+            excludedDirectoryNames,
+          )) {
+            continue;
+          }
+          await walk(entity, root);
+          continue;
+        }
         if (entity is! File ||
-            !MediaLibraryEntryFactory.isImportableVideo(entity.path)) {
+            !MediaLibraryEntryFactory.isImportableVideo(
+              entity.path,
+              extras,
+              excludedDirectoryNames,
+              root,
+            ) ||
+            MediaLibraryEntryFactory.isIncompleteDownload(
+              entity.path,
+              siblingNames: siblingNames,
+            )) {
           continue;
         }
 
@@ -92,6 +124,15 @@ class LibraryScannerService {
       }
     }
 
+    for (final root in roots) {
+      final dir = Directory(root);
+      if (!await dir.exists()) {
+        missingFolders++;
+        continue;
+      }
+      await walk(dir, root);
+    }
+
     for (final show in scannedShows.values) {
       await _repo.consolidateTvShow(show);
     }
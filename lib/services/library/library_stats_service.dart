@@ -0,0 +1,124 @@
+import '../../data/models/media.dart';
+import '../../data/models/release_info.dart';
+import '../../data/repositories/episode_repository.dart';
+import '../../data/repositories/media_repository.dart';
+
+/// Aggregate figures for the library, as shown in the settings stats dialog.
+///
+/// File sizes aren't stored by the scanners, so the resolution and codec
+/// breakdowns count files rather than bytes.
+class LibraryStats {
+  const LibraryStats({
+    required this.movieCount,
+    required this.showCount,
+    required this.episodeCount,
+    required this.byYear,
+    required this.byResolution,
+    required this.byCodec,
+    required this.unmatched,
+  });
+
+  final int movieCount;
+  final int showCount;
+  final int episodeCount;
+
+  /// Titles per release year, newest first; items without a year are left out.
+  final Map<String, int> byYear;
+
+  /// Files per resolution badge (`4K`, `1080p`, ...), `未知` when the name
+  /// carries none.
+  final Map<String, int> byResolution;
+
+  /// Files per codec (`HEVC`, `AVC`, ...), `未知` when the name carries none.
+  final Map<String, int> byCodec;
+
+  /// Titles that never matched TMDB, sorted by title.
+  final List<Media> unmatched;
+
+  int get titleCount => movieCount + showCount;
+
+  Map<String, dynamic> toJson() => {
+    'movies': movieCount,
+    'shows': showCount,
+    'episodes': episodeCount,
+    'byYear': byYear,
+    'byResolution': byResolution,
+    'byCodec': byCodec,
+    'unmatched': [
+      for (final media in unmatched) {'title': media.title, 'path': media.path},
+    ],
+  };
+}
+
+/// Builds [LibraryStats] from the local database; nothing is read from the
+/// sources themselves.
+class LibraryStatsService {
+  LibraryStatsService(this._media, this._episodes);
+
+  final MediaRepository _media;
+  final EpisodeRepository _episodes;
+
+  static const unknownLabel = '未知';
+
+  Future<LibraryStats> collect() async {
+    final items = await _media.browse(deduplicateShows: false);
+    var movies = 0;
+    var shows = 0;
+    var episodes = 0;
+    final byYear = <String, int>{};
+    final byResolution = <String, int>{};
+    final byCodec = <String, int>{};
+    final unmatched = <Media>[];
+
+    void countFile(String filePath) {
+      final info = ReleaseInfo.parse(filePath);
+      final resolution = info.resolution;
+      final resolutionLabel = resolution == null
+          ? unknownLabel
+          : resolution == 2160
+          ? '4K'
+          : '${resolution}p';
+      byResolution.update(resolutionLabel, (n) => n + 1, ifAbsent: () => 1);
+      byCodec.update(
+        info.codec ?? unknownLabel,
+        (n) => n + 1,
+        ifAbsent: () => 1,
+      );
+    }
+
+    for (final media in items) {
+      if (media.type == MediaType.tv) {
+        shows++;
+        for (final episode in await _episodes.getByShow(media.id)) {
+          episodes++;
+          countFile(episode.path);
+        }
+      } else {
+        movies++;
+        countFile(media.path);
+      }
+      if (media.year.isNotEmpty) {
+        byYear.update(media.year, (n) => n + 1, ifAbsent: () => 1);
+      }
+      if (media.tmdbId == null) unmatched.add(media);
+    }
+
+    unmatched.sort((a, b) => a.title.compareTo(b.title));
+    final years = byYear.keys.toList()..sort((a, b) => b.compareTo(a));
+    return LibraryStats(
+      movieCount: movies,
+      showCount: shows,
+      episodeCount: episodes,
+      byYear: {for (final year in years) year: byYear[year]!},
+      byResolution: _byCount(byResolution),
+      byCodec: _byCount(byCodec),
+      unmatched: List.unmodifiable(unmatched),
+    );
+  }
+
+  static Map<String, int> _byCount(Map<String, int> counts) {
+    final entries = counts.entries.toList()
+      ..sort((a, b) => b.value.compareTo(a.value));
+    return Map.fromEntries(entries);
+  }
+}
@@ -0,0 +1,271 @@
+import 'dart:io';
+
+import 'package:path/path.dart' as p;
+
+import '../../data/models/media.dart';
+import '../../data/repositories/episode_repository.dart';
+import '../../data/repositories/media_repository.dart';
+import '../smb/smb_path.dart';
+import '../smb/smb_service.dart';
+
+/// What is wrong with a library entry's file. [name] is the stable code for
+/// logs and exports; [label] is for display.
+enum LibraryIssueCode {
+  missing('文件不存在'),
+  empty('文件为空'),
+  unreadable('无法读取');
+
+  const LibraryIssueCode(this.label);
+
+  final String label;
+}
+
+/// One movie or episode whose file no longer checks out on its source.
+class LibraryIssue {
+  const LibraryIssue({
+    required this.code,
+    required this.mediaId,
+    required this.title,
+    required this.path,
+    this.episodeId,
+    this.detail = '',
+  });
+
+  final LibraryIssueCode code;
+  final String mediaId;
+
+  /// Set when the issue is an episode of the show [mediaId].
+  final String? episodeId;
+  final String title;
+  final String path;
+
+  /// The underlying error for [LibraryIssueCode.unreadable].
+  final String detail;
+}
+
+class LibraryValidationReport {
+  const LibraryValidationReport({
+    required this.checked,
+    required this.skipped,
+    required this.issues,
+    this.unreachableRoots = const [],
+  });
+
+  /// Files that were looked up on their source.
+  final int checked;
+
+  /// Files on sources that can't be checked from here: WebDAV, Emby, Plex,
+  /// an SMB server other than the connected one, or a root listed in
+  /// [unreachableRoots].
+  final int skipped;
+  final List<LibraryIssue> issues;
+
+  /// Drives, mounts and SMB shares that were gone while their files were
+  /// checked. Their files are skipped rather than reported missing, so an
+  /// unplugged disk can't empty the library through [LibraryIssueCode.missing].
+  final List<String> unreachableRoots;
+}
+
+/// Cross-checks stored movie and episode paths against their local folder or
+/// the connected SMB share, reporting missing, zero-byte, and unreadable
+/// files so they can be cleaned out of the library.
+class LibraryValidationService {
+  LibraryValidationService(
+    this._media,
+    this._episodes, [
+    this._smb,
+    this.localRoots = const [],
+  ]);
+
+  final MediaRepository _media;
+  final EpisodeRepository _episodes;
+  final SmbService? _smb;
+
+  /// Scanned local folders. A missing file under one is only reported when
+  /// the folder itself is still there; otherwise the folder is unreachable.
+  final List<String> localRoots;
+
+  /// Lookups in flight at once; kept low so a NAS isn't flooded with stats.
+  static const concurrency = 4;
+
+  /// Roots probed during the current [validate], keyed by path or URL.
+  final _roots = <String, Future<bool>>{};
+
+  Future<LibraryValidationReport> validate() async {
+    _roots.clear();
+    final targets = <_Target>[];
+    final items = await _media.browse(deduplicateShows: false);
+    for (final media in items) {
+      if (media.type != MediaType.tv) {
+        targets.add(_Target(media, null, media.path));
+        continue;
+      }
+      for (final episode in await _episodes.getByShow(media.id)) {
+        targets.add(_Target(media, episode.id, episode.path));
+      }
+    }
+
+    var checked = 0;
+    var skipped = 0;
+    final issues = <LibraryIssue>[];
+    for (var start = 0; start < targets.length; start += concurrency) {
+      final batch = targets.skip(start).take(concurrency).toList();
+      final results = await Future.wait(batch.map(_check));
+      for (var i = 0; i < batch.length; i++) {
+        final result = results[i];
+        if (result == null) {
+          skipped++;
+          continue;
+        }
+        checked++;
+        final (code, detail) = result;
+        if (code == null) continue;
+        final target = batch[i];
+        issues.add(
+          LibraryIssue(
+            code: code,
+            mediaId: target.media.id,
+            episodeId: target.episodeId,
+            title: target.media.title,
+            path: target.path,
+            detail: detail,
+          ),
+        );
+      }
+    }
+    final unreachable = <String>[];
+    for (final entry in _roots.entries) {
+      if (!await entry.value) unreachable.add(entry.key);
+    }
+    return LibraryValidationReport(
+      checked: checked,
+      skipped: skipped,
+      issues: List.unmodifiable(issues),
+      unreachableRoots: List.unmodifiable(unreachable),
+    );
+  }
+
+  /// Deletes the entries behind [issues] with [LibraryIssueCode.missing];
+  /// other codes may be transient and are left alone. Returns the number of
+  /// rows removed.
+  Future<int> removeMissing(List<LibraryIssue> issues) async {
+    var removed = 0;
+    for (final issue in issues) {
+      if (issue.code != LibraryIssueCode.missing) continue;
+      final episodeId = issue.episodeId;
+      if (episodeId == null) {
+        await _media.deleteById(issue.mediaId);
+      } else {
+        await _episodes.deleteById(episodeId);
+      }
+      removed++;
+    }
+    return removed;
+  }
+
+  /// Null when [target] can't be checked, including when its root is
+  /// unreachable; otherwise the issue code (null for a healthy file) and
+  /// error detail.
+  Future<(LibraryIssueCode?, String)?> _check(_Target target) async {
+    final path = target.path;
+    if (_isLocal(path)) {
+      try {
+        final stat = await FileStat.stat(path);
+        if (stat.type != FileSystemEntityType.notFound) {
+          return (stat.size == 0 ? LibraryIssueCode.empty : null, '');
+        }
+      } catch (e) {
+        return (LibraryIssueCode.unreadable, '$e');
+      }
+      final root = _localRoot(path);
+      if (root != null && !await _reachable(root, () => _hasEntries(root))) {
+        return null;
+      }
+      return (LibraryIssueCode.missing, '');
+    }
+
+    final smb = _smb;
+    final host = smb?.config?.host;
+    if (smb == null || host == null) return null;
+    final address = SmbAddress.tryParse(path);
+    if (address == null || address.host.toLowerCase() != host.toLowerCase()) {
+      return null;
+    }
+    // Stored SMB URLs carry names verbatim, so a literal `%20` in a file name
+    // must not be decoded the way a pasted `smb://` URL would be.
+    final serverPath = path.toLowerCase().startsWith('smb://')
+        ? _rawServerPath(path)
+        : address.path.serverPath;
+    final share = '/${SmbPath.normalize(serverPath).share}';
+    (LibraryIssueCode?, String) result;
+    try {
+      final file = await smb.openFolder(serverPath);
+      if (file.isExists) {
+        return (file.size == 0 ? LibraryIssueCode.empty : null, '');
+      }
+      result = (LibraryIssueCode.missing, '');
+    } catch (e) {
+      result = (LibraryIssueCode.unreadable, '$e');
+    }
+    final shareUrl = 'smb://${address.host}$share';
+    final reachable = await _reachable(
+      shareUrl,
+      () async => (await smb.openFolder(share)).isExists,
+    );
+    return reachable ? result : null;
+  }
+
+  /// Probes each root once per [validate]; a probe that throws counts as
+  /// unreachable.
+  Future<bool> _reachable(String root, Future<bool> Function() probe) =>
+      _roots.putIfAbsent(root, () async {
+        try {
+          return await probe();
+        } catch (_) {
+          return false;
+        }
+      });
+
+  /// The configured folder or removable volume [path] lives under, or null
+  /// when it is on the system disk and has no such root.
+  String? _localRoot(String path) {
+    String? best;
+    for (final root in localRoots) {
+      if ((p.equals(root, path) || p.isWithin(root, path)) &&
+          (best == null || root.length > best.length)) {
+        best = root;
+      }
+    }
+    return best ?? _mountPattern.firstMatch(path)?.group(1);
+  }
+
+  /// macOS volumes, Linux removable media and mounts, and Windows drives.
+  static final _mountPattern = RegExp(
+    r'^(/Volumes/[^/]+|/(?:run/)?media/[^/]+/[^/]+|/mnt/[^/]+|[A-Za-z]:[\\/])',
+  );
+
+  /// An unmounted mount point is usually left behind as an empty folder, so
+  /// a root only counts as reachable when it exists and has something in it.
+  static Future<bool> _hasEntries(String root) async {
+    final directory = Directory(root);
+    return await directory.exists() && !await directory.list().isEmpty;
+  }
+
+  /// `/share/dir/name` from a stored `smb://host/share/dir/name`, undecoded.
+  static String _rawServerPath(String url) {
+    final rest = url.substring('smb://'.length);
+    final slash = rest.indexOf('/');
+    return slash < 0 ? '/' : rest.substring(slash);
+  }
+
+  static bool _isLocal(String path) =>
+      path.startsWith('/') || RegExp(r'^[A-Za-z]:[\\/]').hasMatch(path);
+}
+
+class _Target {
+  const _Target(this.media, this.episodeId, this.path);
+
+  final Media media;
+  final String? episodeId;
+  final String path;
+}
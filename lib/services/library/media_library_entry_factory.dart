@@ -3,9 +3,11 @@ import 'dart:convert';
 import 'package:path/path.dart' as path;
 import 'package:smb_connect/smb_connect.dart';
 
+import '../../data/models/anime_release_name.dart';
 import '../../data/models/episode.dart';
 import '../../data/models/media.dart';
 import '../smb/smb_service.dart';
+import 'extras_classifier.dart';
 
 /// Parsed source metadata for a media item.
 class SmbMediaSource {
@@ -46,6 +48,24 @@ class EmbyMediaSource {
   final String itemId;
 }
 
+/// Parsed Plex source metadata for a media item.
+class PlexMediaSource {
+  const PlexMediaSource({
+    required this.baseUrl,
+    required this.ratingKey,
+    this.partKey = '',
+  });
+
+  /// The Plex server base URL.
+  final String baseUrl;
+
+  /// The server metadata id of the movie/show/episode.
+  final String ratingKey;
+
+  /// Server-relative media part path to stream; empty for shows.
+  final String partKey;
+}
+
 /// Result of processing a video file: either a standalone movie [Media],
 /// or a TV show [Media] plus its [Episode].
 class LibraryEntry {
@@ -190,37 +210,118 @@ class MediaLibraryEntryFactory {
         _cjkCleanupPattern.hasMatch(title);
   }
 
+  /// NAS/OS housekeeping directories that never hold library media: Synology
+  /// `@eaDir` thumbnails and `#recycle`, QNAP `.@__thumb` and `@Recycle`,
+  /// Windows recycle bin / volume metadata, and macOS index folders. Walkers
+  /// prune these instead of descending into thousands of thumbnail folders.
+  /// Names are compared lowercase.
+  static const defaultExcludedDirectoryNames = <String>{
+    '@eadir',
+    '@recycle',
+    '#recycle',
+    '#snapshot',
+    '@sharebin',
+    '@tmp',
+    '.@__thumb',
+    '\$recycle.bin',
+    'system volume information',
+    '.trash',
+    '.trashes',
+    '.spotlight-v100',
+    '.fseventsd',
+    '.temporaryitems',
+    '.appledouble',
+    'lost+found',
+  };
+
+  /// Whether a walker should skip the directory called [name]. Pass
+  /// [excluded] to override the built-in [defaultExcludedDirectoryNames], as
+  /// `AppConfig.excludedDirectoryNames` does.
+  static bool isExcludedDirectoryName(
+    String name, [
+    Set<String> excluded = defaultExcludedDirectoryNames,
+  ]) {
+    return excluded.contains(name.trim().toLowerCase());
+  }
+
   static bool isVideoPath(String filePath) {
     return videoExtensions.contains(path.extension(filePath).toLowerCase());
   }
 
   /// True for OS junk / metadata files that look like videos but aren't:
-  /// macOS AppleDouble sidecars (`._foo.mkv`), dotfiles, sample clips, and
-  /// the Trash. These must be skipped so they don't pollute the library or
-  /// create phantom duplicates of every real title.
-  static bool isJunkPath(String filePath) {
+  /// macOS AppleDouble sidecars (`._foo.mkv`), dotfiles, sample clips and
+  /// other extras [extras] is confident about, and the Trash. These must be
+  /// skipped so they don't pollute the library or create phantom duplicates
+  /// of every real title. Files beneath a directory in [excluded] are junk
+  /// too, so a walker's own exclusion set also covers files it is handed.
+  /// Folders are only judged below the scanned [root], so a share that is
+  /// itself called `Extras` or `sample` doesn't make the whole library junk.
+  static bool isJunkPath(
+    String filePath, [
+    ExtrasClassifier extras = const ExtrasClassifier(),
+    Set<String> excluded = defaultExcludedDirectoryNames,
+    String root = '',
+  ]) {
     final name = path.basename(filePath);
     if (name.startsWith('._') || name.startsWith('.')) return true;
-    final lower = filePath.toLowerCase();
-    if (lower.contains('/.trash') ||
-        lower.contains('/\$recycle.bin') ||
-        lower.contains('/@eadir')) {
+    final relative = pathBelowRoot(filePath, root);
+    final lower = relative.toLowerCase();
+    if (lower.contains('/.trash') || lower.split('/').any(excluded.contains)) {
       return true;
     }
-    // "sample"/"trailer" clips that sit beside the real file.
-    final base = path.basenameWithoutExtension(name).toLowerCase();
-    if (base == 'sample' ||
-        base.endsWith('-sample') ||
-        base.endsWith('.sample') ||
-        base == 'trailer') {
-      return true;
+    // Samples, trailers and featurettes that sit beside the real file.
+    return extras.shouldSkip(relative);
+  }
+
+  /// A path worth importing: a real video that isn't OS junk or an extra.
+  static bool isImportableVideo(
+    String filePath, [
+    ExtrasClassifier extras = const ExtrasClassifier(),
+    Set<String> excluded = defaultExcludedDirectoryNames,
+    String root = '',
+  ]) => isVideoPath(filePath) && !isJunkPath(filePath, extras, excluded, root);
+
+  /// [filePath] with `/` separators, cut down to the part below [root] (with
+  /// its leading `/`) when it lies inside it.
+  static String pathBelowRoot(String filePath, String root) {
+    final normalized = filePath.replaceAll('\\', '/');
+    var base = root.trim().replaceAll('\\', '/');
+    while (base.endsWith('/')) {
+      base = base.substring(0, base.length - 1);
     }
-    return false;
+    if (base.isEmpty || !normalized.startsWith('$base/')) return normalized;
+    return normalized.substring(base.length);
   }
 
-  /// A path worth importing: a real video that isn't OS junk.
-  static bool isImportableVideo(String filePath) =>
-      isVideoPath(filePath) && !isJunkPath(filePath);
+  /// Suffixes download clients give a partial file or its control file:
+  /// `.part` (Firefox, Transmission), `.!qB` (qBittorrent), `.!ut` (uTorrent),
+  /// `.crdownload` (Chrome) and `.aria2` (aria2's sidecar beside the video).
+  static const downloadMarkerSuffixes = {
+    '.part',
+    '.!qb',
+    '.!ut',
+    '.crdownload',
+    '.aria2',
+  };
+
+  static bool isDownloadMarker(String name) {
+    final lower = name.toLowerCase();
+    return downloadMarkerSuffixes.any(lower.endsWith);
+  }
+
+  /// Whether [filePath] looks like a download still in progress: a marker
+  /// such as `Dune.mkv.aria2` is among [siblingNames]. Scans skip these; the
+  /// next scan imports them once the marker is gone. Modification time isn't
+  /// used, since a file that was just copied in is as fresh as a partial one.
+  static bool isIncompleteDownload(
+    String filePath, {
+    Set<String> siblingNames = const {},
+  }) {
+    final name = path.basename(filePath).toLowerCase();
+    return downloadMarkerSuffixes.any(
+      (suffix) => siblingNames.contains('$name$suffix'),
+    );
+  }
 
   static LibraryEntry fromLocalPath(String filePath) {
     final normalized = path.normalize(filePath);
@@ -587,9 +688,149 @@ class MediaLibraryEntryFactory {
     }
   }
 
+  /// Builds a flat movie/show [Media] from a Plex item. Episodes come from
+  /// [fromPlexEpisode], mirroring the Emby import.
+  static Media fromPlexMovieOrShow({
+    required String baseUrl,
+    required String ratingKey,
+    required String title,
+    required String year,
+    required bool isShow,
+    String partKey = '',
+    String? posterUrl,
+    String? overview,
+  }) {
+    return Media(
+      id: 'plex|$baseUrl|$ratingKey',
+      title: title,
+      year: year,
+      type: isShow ? MediaType.tv : MediaType.movie,
+      path: 'plex|$baseUrl|$ratingKey',
+      fullPath: partKey.isEmpty ? ratingKey : partKey,
+      posterPath: posterUrl,
+      detailsJson: jsonEncode({
+        'source': {
+          'kind': 'plex',
+          'base': baseUrl,
+          'ratingKey': ratingKey,
+          if (partKey.isNotEmpty) 'partKey': partKey,
+        },
+        if (overview != null && overview.isNotEmpty) 'overview': overview,
+      }),
+    );
+  }
+
+  static const _plexEpisodeIdPrefix = 'plex-ep|';
+
+  /// Builds an [Episode] from a Plex episode belonging to [showId] (the local
+  /// show media id). The part key is kept as the playable path.
+  static Episode fromPlexEpisode({
+    required String showId,
+    required String ratingKey,
+    required String partKey,
+    required int seasonNumber,
+    required int episodeNumber,
+    required String title,
+  }) {
+    return Episode(
+      id: '$_plexEpisodeIdPrefix$ratingKey',
+      showId: showId,
+      seasonNumber: seasonNumber,
+      episodeNumber: episodeNumber,
+      title: title,
+      path: partKey,
+      fullPath: partKey,
+    );
+  }
+
+  /// Extracts Plex source info from a stored media item, or null.
+  static PlexMediaSource? plexSourceFor(Media media) {
+    final raw = media.detailsJson;
+    if (raw == null || raw.isEmpty) return null;
+    try {
+      final decoded = jsonDecode(raw);
+      if (decoded is! Map<String, dynamic>) return null;
+      final source = decoded['source'];
+      if (source is! Map<String, dynamic>) return null;
+      if (source['kind'] != 'plex') return null;
+
+      final base = source['base']?.toString() ?? '';
+      final ratingKey = source['ratingKey']?.toString() ?? '';
+      if (base.isEmpty || ratingKey.isEmpty) return null;
+      return PlexMediaSource(
+        baseUrl: base,
+        ratingKey: ratingKey,
+        partKey: source['partKey']?.toString() ?? '',
+      );
+    } catch (_) {
+      return null;
+    }
+  }
+
+  /// Builds library items from a DLNA video item. [server] identifies the
+  /// MediaServer (its description URL); [titlePath] joins the container
+  /// titles above the item and the item's own title, e.g.
+  /// `/Video/Dark/Dark.S01E01`. The item's HTTP [resourceUrl] is stored as
+  /// the playable path, so playback needs no session.
+  static LibraryEntry fromDlnaItem({
+    required String server,
+    required String titlePath,
+    required Uri resourceUrl,
+  }) {
+    // DLNA titles usually drop the extension; restore one so the same
+    // filename-based parsing as the file sources applies.
+    var logicalPath = _logicalPath(titlePath);
+    if (!isVideoPath(logicalPath)) {
+      final extension = path.extension(resourceUrl.path).toLowerCase();
+      logicalPath += videoExtensions.contains(extension) ? extension : '.mkv';
+    }
+    final basename = path.basenameWithoutExtension(logicalPath);
+    final type = _detectType(logicalPath, basename);
+    final title = _titleFor(type, logicalPath, basename);
+    final year = _extractYear(logicalPath);
+    final url = resourceUrl.toString();
+    final details = jsonEncode({
+      'source': {'kind': 'dlna', 'server': server},
+    });
+
+    if (type == MediaType.tv) {
+      final showId = _sourceScopedTvShowId('dlna', server.toLowerCase(), title);
+      final media = Media(
+        id: showId,
+        title: title,
+        year: year,
+        type: MediaType.tv,
+        path: showId,
+        fullPath: _tvShowDirectoryFromPath(logicalPath),
+        detailsJson: details,
+      );
+      final episode = _parseEpisode(
+        id: 'dlna|$url',
+        showId: showId,
+        logicalPath: logicalPath,
+        basename: basename,
+        filePath: url,
+        fullPath: url,
+      );
+      return LibraryEntry(media: media, episode: episode);
+    }
+
+    final media = Media(
+      id: 'dlna|$url',
+      title: title,
+      year: year,
+      type: type,
+      path: url,
+      fullPath: url,
+      detailsJson: details,
+    );
+    return LibraryEntry(media: media);
+  }
+
   /// Builds a resolver-ready [Media] for playing a single [episode], inheriting
-  /// the parent [show]'s source (SMB/WebDAV/local) but pointing at the episode
-  /// file. Without this, network episodes would expose a non-playable id URI.
+  /// the parent [show]'s source (SMB/WebDAV/Emby/Plex/local) but pointing at
+  /// the episode file. Without this, network episodes would expose a
+  /// non-playable id URI.
   static Media episodePlayableMedia(Episode episode, Media show) {
     final smb = smbSourceFor(show);
     if (smb != null) {
@@ -652,6 +893,29 @@ class MediaLibraryEntryFactory {
       );
     }
 
+    final plex = plexSourceFor(show);
+    if (plex != null) {
+      final ratingKey = episode.id.startsWith(_plexEpisodeIdPrefix)
+          ? episode.id.substring(_plexEpisodeIdPrefix.length)
+          : episode.id;
+      return Media(
+        id: episode.id,
+        title: episode.title,
+        year: '',
+        type: MediaType.unknown,
+        path: episode.path,
+        fullPath: episode.fullPath,
+        detailsJson: jsonEncode({
+          'source': {
+            'kind': 'plex',
+            'base': plex.baseUrl,
+            'ratingKey': ratingKey,
+            'partKey': episode.fullPath ?? episode.path,
+          },
+        }),
+      );
+    }
+
     // Local episode: path/fullPath are directly playable.
     return Media(
       id: episode.id,
@@ -676,6 +940,17 @@ class MediaLibraryEntryFactory {
     final match =
         _episodePattern.firstMatch(basename) ??
         _episodePattern.firstMatch(logicalPath);
+    final anime = match == null ? AnimeReleaseName.tryParse(basename) : null;
+    if (anime != null) {
+      return Episode(
+        id: id,
+        showId: showId,
+        seasonNumber: anime.season ?? _inferSeasonFromPath(logicalPath) ?? 1,
+        episodeNumber: anime.episode,
+        path: filePath,
+        fullPath: fullPath,
+      );
+    }
     if (match == null) {
       final numericEpisode = _numericEpisodeNumber(basename);
       if (numericEpisode != null) {
@@ -910,6 +1185,7 @@ class MediaLibraryEntryFactory {
     }
     if (_episodePattern.hasMatch(basename) ||
         numericEpisode ||
+        AnimeReleaseName.tryParse(basename) != null ||
         lower.contains('/tv/') ||
         lower.contains('/shows/') ||
         lower.contains('/series/') ||
@@ -1014,6 +1290,15 @@ class MediaLibraryEntryFactory {
         .where((segment) => segment.isNotEmpty)
         .toList(growable: false);
 
+    // Fansub releases carry the series name in the file itself
+    // (`[SubsPlease] Frieren - 01 (1080p).mkv`) while their folders are often
+    // named after the group or batch, so the file name wins.
+    final anime = AnimeReleaseName.tryParse(fallback);
+    if (anime != null) {
+      final candidate = _cleanTitle(anime.title);
+      if (_isUsableShowTitle(candidate)) return candidate;
+    }
+
     // Strategy 0: Release-style season pack under a dump/inbox root
     // (…/aria2-downloads/Genius.S01.1080p…/ep → "Genius"). Only when the
     // parent is NOT a real show pack (e.g. 权力的游戏… still wins below).
@@ -0,0 +1,104 @@
+import '../../data/repositories/episode_repository.dart';
+import '../../data/repositories/media_repository.dart';
+import '../plex/plex_service.dart';
+import 'media_library_entry_factory.dart';
+
+/// Summary returned after importing a Plex library.
+class PlexImportResult {
+  const PlexImportResult({
+    required this.movieCount,
+    required this.tvCount,
+    required this.episodeCount,
+    required this.mediaIds,
+  });
+
+  final int movieCount;
+  final int tvCount;
+  final int episodeCount;
+  final List<String> mediaIds;
+
+  int get importedItems => movieCount + tvCount;
+}
+
+/// Imports a Plex server's movies and shows (with episodes) into the local
+/// library, producing the same media items as the other importers. Items keep
+/// a `plex` source so playback can build a stream URL with the live token.
+class PlexLibraryImportService {
+  PlexLibraryImportService(this._plex, this._repo, [this._episodeRepo]);
+
+  final PlexService _plex;
+  final MediaRepository _repo;
+  final EpisodeRepository? _episodeRepo;
+
+  Future<PlexImportResult> importLibrary() async {
+    final config = _plex.config;
+    if (!_plex.isConnected || config == null) {
+      throw StateError('Plex 未连接');
+    }
+    final base = PlexService.normalizeBase(config.url);
+
+    var movieCount = 0;
+    var tvCount = 0;
+    var episodeCount = 0;
+    final mediaIds = <String>[];
+
+    final items = await _plex.fetchLibrary();
+    for (final item in items) {
+      if (item.ratingKey.isEmpty) continue;
+      final isShow = item.type == 'show';
+      if (!isShow && (item.partKey == null || item.partKey!.isEmpty)) continue;
+      final thumb = item.thumb;
+      final media = MediaLibraryEntryFactory.fromPlexMovieOrShow(
+        baseUrl: base,
+        ratingKey: item.ratingKey,
+        title: item.title,
+        year: item.year,
+        isShow: isShow,
+        partKey: item.partKey ?? '',
+        posterUrl: thumb == null || thumb.isEmpty
+            ? null
+            : _plex.imageUrl(thumb),
+        overview: item.summary,
+      );
+      await _repo.upsert(media);
+      mediaIds.add(media.id);
+
+      if (isShow) {
+        tvCount++;
+        if (_episodeRepo != null) {
+          episodeCount += await _importEpisodes(item.ratingKey, media.id);
+        }
+      } else {
+        movieCount++;
+      }
+    }
+
+    return PlexImportResult(
+      movieCount: movieCount,
+      tvCount: tvCount,
+      episodeCount: episodeCount,
+      mediaIds: mediaIds,
+    );
+  }
+
+  Future<int> _importEpisodes(String showRatingKey, String showMediaId) async {
+    final episodes = await _plex.fetchEpisodes(showRatingKey);
+    var count = 0;
+    for (final ep in episodes) {
+      final partKey = ep.partKey;
+      if (ep.ratingKey.isEmpty || partKey == null || partKey.isEmpty) continue;
+      await _episodeRepo!.upsert(
+        MediaLibraryEntryFactory.fromPlexEpisode(
+          showId: showMediaId,
+          ratingKey: ep.ratingKey,
+          partKey: partKey,
+          seasonNumber: ep.seasonNumber ?? 0,
+          episodeNumber: ep.episodeNumber ?? 0,
+          title: ep.title,
+        ),
+      );
+      count++;
+    }
+    return count;
+  }
+}
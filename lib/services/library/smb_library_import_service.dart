@@ -3,7 +3,9 @@ import 'package:smb_connect/smb_connect.dart';
 import '../../data/models/media.dart';
 import '../../data/repositories/episode_repository.dart';
 import '../../data/repositories/media_repository.dart';
+import '../../data/repositories/scan_checkpoint_repository.dart';
 import '../smb/smb_service.dart';
+import 'extras_classifier.dart';
 import 'media_library_entry_factory.dart';
 
 /// Summary returned after importing an SMB folder into the media library.
@@ -16,6 +18,7 @@ class SmbLibraryImportResult {
     required this.episodeCount,
     required this.rootPath,
     required this.mediaIds,
+    this.resumed = false,
   });
 
   final int scannedFiles;
@@ -25,15 +28,48 @@ class SmbLibraryImportResult {
   final int episodeCount;
   final String rootPath;
   final List<String> mediaIds;
+
+  /// True when folders finished by an interrupted earlier scan were skipped;
+  /// the counts then cover only what this run walked.
+  final bool resumed;
 }
 
 /// Recursively imports SMB video files from the active session into the library.
+///
+/// With a [ScanCheckpointRepository], finished folders are recorded as the
+/// walk goes, so a scan that dies halfway (dropped Wi-Fi, app quit) resumes
+/// where it stopped the next time the same root is imported. Each save
+/// rewrites the whole set, so they are batched by [checkpointEvery] and
+/// [checkpointInterval]; a failed walk saves what is pending first.
+///
+/// Listings are consumed one server page at a time, so memory per folder is
+/// bounded by its subfolder count rather than its file count; the returned
+/// [SmbLibraryImportResult.mediaIds] still grows with the whole import.
 class SmbLibraryImportService {
-  SmbLibraryImportService(this._smb, this._repo, [this._episodeRepo]);
+  SmbLibraryImportService(
+    this._smb,
+    this._repo, [
+    this._episodeRepo,
+    this.excludedDirectoryNames =
+        MediaLibraryEntryFactory.defaultExcludedDirectoryNames,
+    this._checkpoints,
+    this.extras = const ExtrasClassifier(),
+  ]);
 
   final SmbService _smb;
   final MediaRepository _repo;
   final EpisodeRepository? _episodeRepo;
+  final ScanCheckpointRepository? _checkpoints;
+
+  /// Lowercase directory names pruned during the walk (NAS thumbnails,
+  /// recycle bins, ...).
+  final Set<String> excludedDirectoryNames;
+
+  /// Decides which samples/trailers/featurettes are left out of the import.
+  final ExtrasClassifier extras;
+
+  static const checkpointEvery = 25;
+  static const checkpointInterval = Duration(seconds: 5);
 
   Future<SmbLibraryImportResult> importFolder(SmbFile root) async {
     final config = _smb.config;
@@ -49,47 +85,118 @@ class SmbLibraryImportService {
     final mediaIds = <String>[];
     final visited = <String>{};
     final scannedShows = <String, Media>{};
+    final scanId = 'smb|${config.host.toLowerCase()}|${root.path}';
+    final checkpoint = await _checkpoints?.load(scanId);
+    final completed = {...?checkpoint?.completed};
+    final resumed = completed.isNotEmpty;
+    // Items from the interrupted run are reported again, so the caller
+    // enriches them and their shows are consolidated below.
+    mediaIds.addAll(checkpoint?.mediaIds ?? const []);
+    for (final id in mediaIds.toSet()) {
+      final media = await _repo.getById(id);
+      if (media != null && media.type == MediaType.tv) {
+        scannedShows[id] = media;
+      }
+    }
+    var unsaved = 0;
+    final sinceSave = Stopwatch()..start();
+
+    Future<void> saveCheckpoint() async {
+      if (unsaved == 0) return;
+      unsaved = 0;
+      sinceSave.reset();
+      await _checkpoints?.save(
+        scanId,
+        ScanCheckpoint(completed: completed, mediaIds: mediaIds),
+      );
+    }
 
     Future<void> walk(SmbFile folder) async {
+      if (completed.contains(folder.path)) return;
       if (!visited.add(folder.path)) return;
 
-      final entries = await _smb.listChildren(folder);
-      for (final entry in entries) {
-        if (entry.isDirectory()) {
-          await walk(entry);
-          continue;
-        }
-        if (!MediaLibraryEntryFactory.isImportableVideo(entry.path)) continue;
-
-        scannedFiles++;
-        final libraryEntry = MediaLibraryEntryFactory.fromSmbFile(
-          config: config,
-          file: entry,
-        );
-        await _repo.upsertScanned(libraryEntry.media);
-        importedItems++;
-        mediaIds.add(libraryEntry.media.id);
-
-        if (libraryEntry.hasEpisode && _episodeRepo != null) {
-          await _episodeRepo.upsert(libraryEntry.episode!);
-          episodeCount++;
+      // Files are imported page by page as the listing streams in; only the
+      // subfolders are kept, and walked once the listing is closed.
+      final subfolders = <SmbFile>[];
+      // Only markers on this or an earlier page are seen, so a download whose
+      // marker is listed on a later page than the video is imported early.
+      final siblingNames = <String>{};
+      await for (final page in _smb.listChildrenPaged(folder)) {
+        for (final entry in page) {
+          if (MediaLibraryEntryFactory.isDownloadMarker(entry.name)) {
+            siblingNames.add(entry.name.toLowerCase());
+          }
         }
+        for (final entry in page) {
+          if (entry.isDirectory()) {
+            if (!MediaLibraryEntryFactory.isExcludedDirectoryName(
+              entry.name,
+              excludedDirectoryNames,
+            )) {
+              subfolders.add(entry);
+            }
+            continue;
+          }
+          if (!MediaLibraryEntryFactory.isImportableVideo(
+                entry.path,
+                extras,
+                excludedDirectoryNames,
+                root.path,
+              ) ||
+              MediaLibraryEntryFactory.isIncompleteDownload(
+                entry.path,
+                siblingNames: siblingNames,
+              )) {
+            continue;
+          }
+
+          scannedFiles++;
+          final libraryEntry = MediaLibraryEntryFactory.fromSmbFile(
+            config: config,
+            file: entry,
+          );
+          await _repo.upsertScanned(libraryEntry.media);
+          importedItems++;
+          mediaIds.add(libraryEntry.media.id);
 
-        switch (libraryEntry.media.type) {
-          case MediaType.movie:
-            movieCount++;
-            break;
-          case MediaType.tv:
-            tvCount++;
-            scannedShows[libraryEntry.media.id] = libraryEntry.media;
-            break;
-          case MediaType.unknown:
-            break;
+          if (libraryEntry.hasEpisode && _episodeRepo != null) {
+            await _episodeRepo.upsert(libraryEntry.episode!);
+            episodeCount++;
+          }
+
+          switch (libraryEntry.media.type) {
+            case MediaType.movie:
+              movieCount++;
+              break;
+            case MediaType.tv:
+              tvCount++;
+              scannedShows[libraryEntry.media.id] = libraryEntry.media;
+              break;
+            case MediaType.unknown:
+              break;
+          }
         }
       }
+      for (final subfolder in subfolders) {
+        await walk(subfolder);
+      }
+
+      // Post-order: a folder counts as done only once its subtree is.
+      completed.add(folder.path);
+      unsaved++;
+      if (unsaved >= checkpointEvery ||
+          sinceSave.elapsed >= checkpointInterval) {
+        await saveCheckpoint();
+      }
     }
 
-    await walk(root);
+    try {
+      await walk(root);
+    } catch (_) {
+      await saveCheckpoint();
+      rethrow;
+    }
+    await _checkpoints?.clear(scanId);
     for (final show in scannedShows.values) {
       await _repo.consolidateTvShow(show);
     }
@@ -102,6 +209,7 @@ class SmbLibraryImportService {
       episodeCount: episodeCount,
       rootPath: root.path,
       mediaIds: mediaIds,
+      resumed: resumed,
     );
   }
 }
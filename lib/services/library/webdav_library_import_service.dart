@@ -2,6 +2,7 @@ import '../../data/models/media.dart';
 import '../../data/repositories/episode_repository.dart';
 import '../../data/repositories/media_repository.dart';
 import '../webdav/webdav_service.dart';
+import 'extras_classifier.dart';
 import 'media_library_entry_factory.dart';
 
 /// Summary returned after importing a WebDAV folder into the media library.
@@ -28,12 +29,26 @@ class WebDavLibraryImportResult {
 /// Recursively imports WebDAV video files from the active session into the
 /// library. Mirrors [SmbLibraryImportService] but over plain HTTP(S).
 class WebDavLibraryImportService {
-  WebDavLibraryImportService(this._dav, this._repo, [this._episodeRepo]);
+  WebDavLibraryImportService(
+    this._dav,
+    this._repo, [
+    this._episodeRepo,
+    this.excludedDirectoryNames =
+        MediaLibraryEntryFactory.defaultExcludedDirectoryNames,
+    this.extras = const ExtrasClassifier(),
+  ]);
 
   final WebDavService _dav;
   final MediaRepository _repo;
   final EpisodeRepository? _episodeRepo;
 
+  /// Lowercase directory names pruned during the walk (NAS thumbnails,
+  /// recycle bins, ...).
+  final Set<String> excludedDirectoryNames;
+
+  /// Decides which samples/trailers/featurettes are left out of the import.
+  final ExtrasClassifier extras;
+
   Future<WebDavLibraryImportResult> importFolder(String rootPath) async {
     final config = _dav.config;
     if (!_dav.isConnected || config == null) {
@@ -54,12 +69,34 @@ class WebDavLibraryImportService {
       if (!visited.add(dirPath)) return;
 
       final entries = await _dav.listDir(dirPath);
+      final siblingNames = {
+        for (final entry in entries)
+          if (MediaLibraryEntryFactory.isDownloadMarker(entry.name))
+            entry.name.toLowerCase(),
+      };
       for (final entry in entries) {
         if (entry.isDir) {
+          if (MediaLibraryEntryFactory.isExcludedDirectoryName(
+            entry.name,
+            excludedDirectoryNames,
+          )) {
+            continue;
+          }
           await walk(entry.path);
           continue;
         }
-        if (!MediaLibraryEntryFactory.isImportableVideo(entry.path)) continue;
+        if (!MediaLibraryEntryFactory.isImportableVideo(
+              entry.path,
+              extras,
+              excludedDirectoryNames,
+              rootPath,
+            ) ||
+            MediaLibraryEntryFactory.isIncompleteDownload(
+              entry.path,
+              siblingNames: siblingNames,
+            )) {
+          continue;
+        }
 
         scannedFiles++;
         final libraryEntry = MediaLibraryEntryFactory.fromWebDavFile(
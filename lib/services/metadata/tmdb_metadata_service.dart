@@ -5,6 +5,7 @@ import 'dart:io';
 import 'package:http/http.dart' as http;
 
 import '../../core/formatters/rating_formatter.dart';
+import '../../core/formatters/title_normalizer.dart';
 import '../../data/models/media.dart';
 import '../library/media_library_entry_factory.dart';
 
@@ -76,26 +77,54 @@ class TmdbCastMember {
 }
 
 /// Thin TMDB client for search + details lookups.
+///
+/// Successful responses are cached in memory for [cacheTtl], so a library
+/// rescan or reopening a detail page doesn't re-spend TMDB's rate budget on
+/// titles already looked up this session.
 class TmdbMetadataService {
   TmdbMetadataService(
     this._client, {
     Uri? baseUri,
     this.imageBaseUrl = 'https://image.tmdb.org/t/p',
+    this.cacheTtl = const Duration(hours: 6),
   }) : baseUri = baseUri ?? Uri.parse('https://api.themoviedb.org/3');
 
   final http.Client _client;
   final Uri baseUri;
   final String imageBaseUrl;
+  final Duration cacheTtl;
+
+  static const _maxCachedResponses = 500;
+
+  /// Longest `Retry-After` we honour before giving up on a 429.
+  static const _maxRetryAfter = Duration(seconds: 10);
+
+  /// Keyed by the full request URI, which carries the API key and language,
+  /// so changing either never serves a stale response.
+  final _cache = <Uri, ({DateTime storedAt, http.Response response})>{};
 
   /// GET with retries for transient iOS socket failures (`Bad file descriptor`,
-  /// connection resets, etc.).
+  /// connection resets, etc.) and for 429s, which wait out `Retry-After`.
   Future<http.Response> _get(Uri uri, {int maxAttempts = 3}) async {
+    final cached = _cache[uri];
+    if (cached != null) {
+      if (DateTime.now().difference(cached.storedAt) < cacheTtl) {
+        return cached.response;
+      }
+      _cache.remove(uri);
+    }
+
     Object? lastError;
     for (var attempt = 1; attempt <= maxAttempts; attempt++) {
       try {
         final response = await _client
             .get(uri)
             .timeout(const Duration(seconds: 20));
+        if (response.statusCode == 429 && attempt < maxAttempts) {
+          await Future<void>.delayed(_retryAfter(response, attempt));
+          continue;
+        }
+        if (response.statusCode == 200) _store(uri, response);
         return response;
       } on TimeoutException catch (e) {
         lastError = e;
@@ -117,6 +146,22 @@ class TmdbMetadataService {
     throw lastError ?? StateError('TMDB request failed: $uri');
   }
 
+  void _store(Uri uri, http.Response response) {
+    if (_cache.length >= _maxCachedResponses) {
+      // Maps iterate in insertion order, so this evicts the oldest entry.
+      _cache.remove(_cache.keys.first);
+    }
+    _cache[uri] = (storedAt: DateTime.now(), response: response);
+  }
+
+  static Duration _retryAfter(http.Response response, int attempt) {
+    final seconds = int.tryParse(response.headers['retry-after'] ?? '');
+    final wait = seconds == null
+        ? Duration(milliseconds: 500 * attempt)
+        : Duration(seconds: seconds);
+    return wait > _maxRetryAfter ? _maxRetryAfter : wait;
+  }
+
   /// Fetch metadata from TMDB. If [searchTitle] is provided (e.g. from AI
   /// recognition), it's used instead of media.title for the TMDB query.
   Future<TmdbMetadataPayload?> fetchMetadata(
@@ -178,7 +223,9 @@ class TmdbMetadataService {
       MediaType.tv => '/search/tv',
       MediaType.unknown => '/search/multi',
     };
-    final searchQuery = titleOverride ?? media.title;
+    // Full-width letters and traditional characters miss TMDB's zh-CN
+    // titles; search with the folded spelling.
+    final searchQuery = normalizeTitle(titleOverride ?? media.title);
     final year = yearOverride ?? media.year;
 
     // Determine search languages based on query content:
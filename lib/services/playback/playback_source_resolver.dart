@@ -6,6 +6,7 @@ import 'package:path/path.dart' as p;
 import '../../data/models/media.dart';
 import '../emby/emby_service.dart';
 import '../library/media_library_entry_factory.dart';
+import '../plex/plex_service.dart';
 import 'external_subtitle_finder.dart';
 import 'subtitle_text_normalizer.dart';
 import '../smb/smb_proxy_server.dart';
@@ -44,6 +45,8 @@ class PlaybackSourceResolver {
     this.webDav,
     this.emby,
     this.embyConfig,
+    this.plex,
+    this.plexConfig,
   });
 
   final SmbService _smb;
@@ -64,6 +67,12 @@ class PlaybackSourceResolver {
   /// (e.g. after an app restart) before resolving an Emby item.
   final EmbyConfig? Function()? embyConfig;
 
+  /// The live Plex session, used to build token-bearing stream URLs.
+  final PlexService? plex;
+
+  /// Looks up the Plex URL + token so the session can be restored lazily.
+  final PlexConfig? Function()? plexConfig;
+
   Future<PlaybackSource> resolve(Media media) async {
     final smbSource = MediaLibraryEntryFactory.smbSourceFor(media);
     if (smbSource != null) {
@@ -89,9 +98,11 @@ class PlaybackSourceResolver {
 
       await _ensureSmbConnected(smbSource);
       await _proxy.start();
-      final subtitles = await _findSmbSubtitles(playPath);
+      // Stored paths can drift in case from what a case-sensitive share has.
+      final serverPath = await _smb.resolveCasing(playPath) ?? playPath;
+      final subtitles = await _findSmbSubtitles(serverPath);
       return PlaybackSource(
-        _proxy.urlFor(playPath, displayName: p.basename(playPath)),
+        _proxy.urlFor(serverPath, displayName: p.basename(serverPath)),
         subtitles: subtitles,
       );
     }
@@ -136,6 +147,25 @@ class PlaybackSourceResolver {
       return PlaybackSource(session.streamUrl(embySource.itemId));
     }
 
+    final plexSource = MediaLibraryEntryFactory.plexSourceFor(media);
+    if (plexSource != null) {
+      final session = plex;
+      if (session == null) {
+        throw StateError('Plex source is not available');
+      }
+      if (plexSource.partKey.isEmpty) {
+        throw StateError('Plex item has no playable media part');
+      }
+      if (!session.isConnected) {
+        final config = plexConfig?.call();
+        if (config == null || !config.isComplete) {
+          throw StateError('Plex source is not connected');
+        }
+        await session.connect(config);
+      }
+      return PlaybackSource(session.streamUrl(plexSource.partKey));
+    }
+
     final fullPath = media.fullPath;
     if (fullPath != null && fullPath.isNotEmpty) {
       return PlaybackSource(fullPath);
@@ -0,0 +1,195 @@
+import 'dart:convert';
+
+import 'package:http/http.dart' as http;
+
+/// Plex Media Server connection parameters. Plex authenticates with a
+/// long-lived `X-Plex-Token` rather than a username/password exchange.
+class PlexConfig {
+  const PlexConfig({required this.url, this.token = ''});
+
+  /// Server base URL, e.g. `http://192.168.1.10:32400`.
+  final String url;
+  final String token;
+
+  bool get isComplete => url.trim().isNotEmpty && token.trim().isNotEmpty;
+}
+
+/// A movie, show, or episode returned from a Plex library.
+class PlexItem {
+  const PlexItem({
+    required this.ratingKey,
+    required this.title,
+    required this.type,
+    required this.year,
+    this.thumb,
+    this.summary,
+    this.partKey,
+    this.seasonNumber,
+    this.episodeNumber,
+  });
+
+  final String ratingKey;
+  final String title;
+
+  /// Raw Plex metadata type: `movie`, `show`, or `episode`.
+  final String type;
+  final String year;
+
+  /// Server-relative poster path, e.g. `/library/metadata/12/thumb/17000`.
+  final String? thumb;
+  final String? summary;
+
+  /// Server-relative path of the first media part (the playable file); null
+  /// for shows.
+  final String? partKey;
+  final int? seasonNumber;
+  final int? episodeNumber;
+}
+
+/// Thin Plex REST client: validate the token, list movie/show sections, and
+/// build stream / image URLs. Stream URLs embed the token as a query param so
+/// the player needs no extra headers; image URLs are stored in the library, so
+/// they leave it out and the image loader adds it when fetching.
+class PlexService {
+  PlexService(this._client);
+
+  final http.Client _client;
+
+  PlexConfig? _config;
+  String? _baseUrl;
+
+  bool get isConnected => _baseUrl != null;
+  PlexConfig? get config => _config;
+
+  /// Verifies the token by reading the section list.
+  Future<void> connect(PlexConfig config) async {
+    final base = normalizeBase(config.url);
+    final response = await _client.get(
+      _uri(base, '/library/sections', config.token),
+      headers: const {'Accept': 'application/json'},
+    );
+    if (response.statusCode == 401) {
+      throw StateError('Plex 令牌无效');
+    }
+    if (response.statusCode != 200) {
+      throw StateError('连接 Plex 失败（HTTP ${response.statusCode}）');
+    }
+    _config = config;
+    _baseUrl = base;
+  }
+
+  /// Lists all movies and shows across the server's movie/show sections.
+  Future<List<PlexItem>> fetchLibrary() async {
+    final sections = await _getContainer('/library/sections', 'Directory');
+    final items = <PlexItem>[];
+    for (final section in sections) {
+      final type = section['type']?.toString();
+      final key = section['key']?.toString();
+      if (key == null || (type != 'movie' && type != 'show')) continue;
+      final metadata = await _getContainer(
+        '/library/sections/$key/all',
+        'Metadata',
+      );
+      items.addAll(metadata.map(_toItem));
+    }
+    return items;
+  }
+
+  /// Lists every episode of a show (used to populate TV show detail).
+  Future<List<PlexItem>> fetchEpisodes(String showRatingKey) async {
+    final metadata = await _getContainer(
+      '/library/metadata/$showRatingKey/allLeaves',
+      'Metadata',
+    );
+    return metadata.map(_toItem).toList(growable: false);
+  }
+
+  /// Direct-play URL for a media part, with the token embedded.
+  String streamUrl(String partKey) {
+    return _uri(_requireBase(), partKey, _requireToken()).toString();
+  }
+
+  /// Poster URL for a thumb path, without the token.
+  String imageUrl(String thumb) => '${_requireBase()}$thumb';
+
+  /// Query parameter that authenticates requests to the server.
+  static const tokenParameter = 'X-Plex-Token';
+
+  void disconnect() {
+    _config = null;
+    _baseUrl = null;
+  }
+
+  Future<List<Map<String, dynamic>>> _getContainer(
+    String path,
+    String field,
+  ) async {
+    final response = await _client.get(
+      _uri(_requireBase(), path, _requireToken()),
+      headers: const {'Accept': 'application/json'},
+    );
+    if (response.statusCode != 200) {
+      throw StateError('读取 Plex 媒体库失败（HTTP ${response.statusCode}）');
+    }
+    final decoded = jsonDecode(utf8.decode(response.bodyBytes));
+    if (decoded is! Map<String, dynamic>) return const [];
+    final container = decoded['MediaContainer'];
+    if (container is! Map<String, dynamic>) return const [];
+    final raw = container[field];
+    if (raw is! List) return const [];
+    return raw.whereType<Map<String, dynamic>>().toList(growable: false);
+  }
+
+  PlexItem _toItem(Map<String, dynamic> json) {
+    String? partKey;
+    final media = json['Media'];
+    if (media is List && media.isNotEmpty && media.first is Map) {
+      final parts = (media.first as Map)['Part'];
+      if (parts is List && parts.isNotEmpty && parts.first is Map) {
+        partKey = (parts.first as Map)['key']?.toString();
+      }
+    }
+    return PlexItem(
+      ratingKey: json['ratingKey']?.toString() ?? '',
+      title: json['title']?.toString() ?? '',
+      type: json['type']?.toString() ?? '',
+      year: json['year']?.toString() ?? '',
+      thumb: json['thumb']?.toString(),
+      summary: json['summary']?.toString(),
+      partKey: partKey,
+      seasonNumber: (json['parentIndex'] as num?)?.toInt(),
+      episodeNumber: (json['index'] as num?)?.toInt(),
+    );
+  }
+
+  static Uri _uri(String base, String path, String token) {
+    final uri = Uri.parse('$base$path');
+    return uri.replace(
+      queryParameters: {...uri.queryParameters, tokenParameter: token},
+    );
+  }
+
+  String _requireBase() {
+    final base = _baseUrl;
+    if (base == null) throw StateError('Plex 未连接');
+    return base;
+  }
+
+  String _requireToken() {
+    final token = _config?.token;
+    if (token == null) throw StateError('Plex 未连接');
+    return token;
+  }
+
+  /// [url] with a scheme and without trailing slashes.
+  static String normalizeBase(String url) {
+    var result = url.trim();
+    if (!result.startsWith('http://') && !result.startsWith('https://')) {
+      result = 'http://$result';
+    }
+    while (result.endsWith('/')) {
+      result = result.substring(0, result.length - 1);
+    }
+    return result;
+  }
+}
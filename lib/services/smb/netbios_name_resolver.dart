@@ -0,0 +1,145 @@
+import 'dart:async';
+import 'dart:io';
+import 'dart:typed_data';
+
+/// Resolves bare NetBIOS names such as `MYNAS` with a name-query broadcast on
+/// UDP 137, the way Windows Explorer finds a NAS that isn't in DNS.
+///
+/// Only used as a fallback: names that already resolve through the system
+/// resolver, IP literals, and dotted host names are returned unchanged.
+class NetbiosNameResolver {
+  const NetbiosNameResolver();
+
+  static const _port = 137;
+
+  /// `<20>`, the File Server Service suffix an SMB host registers.
+  static const _fileServerSuffix = 0x20;
+
+  /// How long a name nobody answered for is left alone, so each reconnect to
+  /// an unresolvable name doesn't wait out the query timeout again.
+  static const negativeTtl = Duration(seconds: 30);
+
+  /// Upper-cased names whose last query went unanswered, and when. Static
+  /// because the resolver itself is a const value.
+  static final _misses = <String, DateTime>{};
+
+  /// An IPv4 address for [host] when only NetBIOS knows it, otherwise [host]
+  /// itself, so the result can always be passed on as the connect target.
+  Future<String> resolve(
+    String host, {
+    Duration timeout = const Duration(seconds: 2),
+  }) async {
+    if (!isNetbiosName(host)) return host;
+    try {
+      if ((await InternetAddress.lookup(host)).isNotEmpty) return host;
+    } on SocketException {
+      // Not in DNS or the hosts file; ask the LAN.
+    }
+    final key = host.toUpperCase();
+    final missedAt = _misses[key];
+    if (missedAt != null && DateTime.now().difference(missedAt) < negativeTtl) {
+      return host;
+    }
+    final address = await query(host, timeout: timeout);
+    if (address == null) {
+      _misses[key] = DateTime.now();
+      return host;
+    }
+    _misses.remove(key);
+    return address;
+  }
+
+  /// Broadcasts one name query and returns the first answer's address, or
+  /// null when nobody answers within [timeout].
+  Future<String?> query(
+    String name, {
+    Duration timeout = const Duration(seconds: 2),
+  }) async {
+    final socket = await RawDatagramSocket.bind(InternetAddress.anyIPv4, 0);
+    socket.broadcastEnabled = true;
+    final transactionId = DateTime.now().microsecondsSinceEpoch & 0xffff;
+    final answer = Completer<String?>();
+    final subscription = socket.listen((event) {
+      if (event != RawSocketEvent.read) return;
+      final datagram = socket.receive();
+      if (datagram == null || answer.isCompleted) return;
+      final address = parseResponse(datagram.data, transactionId);
+      if (address != null) answer.complete(address);
+    });
+    Timer? timer;
+    try {
+      socket.send(
+        buildQuery(name, transactionId),
+        InternetAddress('255.255.255.255'),
+        _port,
+      );
+      timer = Timer(timeout, () {
+        if (!answer.isCompleted) answer.complete(null);
+      });
+      return await answer.future;
+    } finally {
+      timer?.cancel();
+      await subscription.cancel();
+      socket.close();
+    }
+  }
+
+  /// A single-label name of at most 15 characters; NetBIOS has no dots.
+  static bool isNetbiosName(String host) =>
+      RegExp(r'^[A-Za-z0-9][A-Za-z0-9_-]{0,14}$').hasMatch(host) &&
+      InternetAddress.tryParse(host) == null;
+
+  /// RFC 1002 NAME QUERY REQUEST with the broadcast and recursion bits set.
+  static Uint8List buildQuery(String name, int transactionId) {
+    final builder = BytesBuilder()
+      ..add([transactionId >> 8 & 0xff, transactionId & 0xff])
+      ..add([0x01, 0x10]) // RD | B
+      ..add([0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00])
+      ..add(encodeName(name))
+      ..add([0x00, 0x20, 0x00, 0x01]); // NB, IN
+    return builder.takeBytes();
+  }
+
+  /// First-level encoding: the name upper-cased and space-padded to 15 bytes
+  /// plus the service suffix, each nibble written as `'A' + nibble`.
+  static List<int> encodeName(String name) {
+    final raw = [
+      ...name.toUpperCase().padRight(15).substring(0, 15).codeUnits,
+      _fileServerSuffix,
+    ];
+    return [
+      0x20,
+      for (final byte in raw) ...[0x41 + (byte >> 4), 0x41 + (byte & 0x0f)],
+      0x00,
+    ];
+  }
+
+  /// The first IPv4 address in a positive NAME QUERY RESPONSE matching
+  /// [transactionId]; null for anything else.
+  static String? parseResponse(List<int> data, int transactionId) {
+    if (data.length < 12) return null;
+    if ((data[0] << 8 | data[1]) != transactionId) return null;
+    final isResponse = data[2] & 0x80 != 0;
+    final rcode = data[3] & 0x0f;
+    final answers = data[6] << 8 | data[7];
+    if (!isResponse || rcode != 0 || answers == 0) return null;
+
+    // Skip the answer's name: either a full label sequence or a pointer.
+    var offset = 12;
+    while (offset < data.length && data[offset] != 0) {
+      if (data[offset] & 0xc0 == 0xc0) {
+        offset += 1;
+        break;
+      }
+      offset += data[offset] + 1;
+    }
+    offset += 1;
+    // TYPE, CLASS, TTL, RDLENGTH, then NB_FLAGS before the address.
+    offset += 2 + 2 + 4;
+    if (offset + 2 > data.length) return null;
+    final rdLength = data[offset] << 8 | data[offset + 1];
+    offset += 2;
+    if (rdLength < 6 || offset + 6 > data.length) return null;
+    return data.sublist(offset + 2, offset + 6).join('.');
+  }
+}
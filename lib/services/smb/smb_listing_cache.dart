@@ -0,0 +1,44 @@
+import 'package:smb_connect/smb_connect.dart';
+
+/// Recent SMB folder listings keyed by path, so the browser can go up and back
+/// down without re-querying the NAS for every step. Entries expire after
+/// [ttl]; past [maxEntries] the oldest listing is dropped first.
+class SmbListingCache {
+  SmbListingCache({
+    this.ttl = const Duration(seconds: 30),
+    this.maxEntries = 64,
+    DateTime Function()? now,
+  }) : _now = now ?? DateTime.now;
+
+  final Duration ttl;
+  final int maxEntries;
+  final DateTime Function() _now;
+
+  // Insertion-ordered, so the first key is always the oldest listing.
+  final _entries = <String, (DateTime, List<SmbFile>)>{};
+
+  int get length => _entries.length;
+
+  /// The listing for [path], or null when it was never stored or has expired.
+  List<SmbFile>? get(String path) {
+    final cached = _entries[path];
+    if (cached == null) return null;
+    if (_now().difference(cached.$1) >= ttl) {
+      _entries.remove(path);
+      return null;
+    }
+    return cached.$2;
+  }
+
+  void put(String path, List<SmbFile> entries) {
+    _entries.remove(path);
+    _entries[path] = (_now(), entries);
+    while (_entries.length > maxEntries) {
+      _entries.remove(_entries.keys.first);
+    }
+  }
+
+  void remove(String path) => _entries.remove(path);
+
+  void clear() => _entries.clear();
+}
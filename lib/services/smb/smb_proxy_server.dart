@@ -1,3 +1,4 @@
+import 'dart:collection';
 import 'dart:io';
 import 'dart:math' as math;
 import 'dart:typed_data';
@@ -14,12 +15,26 @@ import '../streaming/range_source.dart';
 /// Binds to loopback only. Source ids are mapped to opaque tokens so the real
 /// path never appears in the URL handed to the player.
 class SmbProxyServer {
-  SmbProxyServer(this._source, {this.initialResponseBytes = 32 * 1024 * 1024});
+  SmbProxyServer(
+    this._source, {
+    this.initialResponseBytes = 32 * 1024 * 1024,
+    this.readAheadChunkBytes = 4 * 1024 * 1024,
+    this.readAheadChunks = 4,
+  });
 
   static const _rangePrefix = 'bytes=';
 
   final RangeSource _source;
   final int initialResponseBytes;
+
+  /// Size of each ranged read issued against the source for one response.
+  final int readAheadChunkBytes;
+
+  /// How many chunk reads may be in flight at once. The next chunks are
+  /// already arriving while VLC consumes the current one, which keeps
+  /// high-bitrate remuxes from stalling on per-read network latency. `1`
+  /// disables read-ahead and streams the range with a single read.
+  final int readAheadChunks;
   HttpServer? _server;
   final Map<String, String> _pathByToken = {};
   final Map<String, String> _tokenByPath = {};
@@ -42,10 +57,7 @@ class SmbProxyServer {
     final token = _tokenByPath[sourceId] ?? (++_counter).toString();
     _pathByToken[token] = sourceId;
     _tokenByPath[sourceId] = token;
-    final suffix = displayName == null || displayName.isEmpty
-        ? ''
-        : '/${Uri.encodeComponent(displayName)}';
-    return 'http://127.0.0.1:${port!}/stream/$token$suffix';
+    return 'http://127.0.0.1:${port!}/stream/$token${_nameSuffix(displayName)}';
   }
 
   /// Registers a small in-memory resource, primarily a subtitle normalized to
@@ -59,8 +71,21 @@ class SmbProxyServer {
     final token = 'memory-${++_counter}';
     _bytesByToken[token] = Uint8List.fromList(bytes);
     _contentTypeByToken[token] = contentType ?? _contentType(displayName);
-    return 'http://127.0.0.1:${port!}/stream/$token/'
-        '${Uri.encodeComponent(displayName)}';
+    return 'http://127.0.0.1:${port!}/stream/$token${_nameSuffix(displayName)}';
+  }
+
+  /// Trailing path segment carrying the file name, which players use for
+  /// titles and extension sniffing. Percent-encoding keeps CJK, emoji, and
+  /// `/` intact as one segment; bare `.`/`..` are dropped because clients
+  /// would collapse them as dot-segments and miss the token.
+  static String _nameSuffix(String? displayName) {
+    if (displayName == null ||
+        displayName.isEmpty ||
+        displayName == '.' ||
+        displayName == '..') {
+      return '';
+    }
+    return '/${Uri.encodeComponent(displayName)}';
   }
 
   Future<Response> _handle(Request request) async {
@@ -118,7 +143,9 @@ class SmbProxyServer {
         return Response(statusCode, headers: headers);
       }
 
-      final body = await _source.read(sourceId, start, end);
+      final body = readAheadChunks > 1 && length > readAheadChunkBytes
+          ? _readAhead(sourceId, start, end)
+          : await _source.read(sourceId, start, end);
       return Response(statusCode, body: body, headers: headers);
     } on FormatException {
       final total = await _source.length(sourceId);
@@ -128,6 +155,42 @@ class SmbProxyServer {
     }
   }
 
+  /// Streams [start]..[end] as consecutive chunks, keeping up to
+  /// [readAheadChunks] reads in flight and yielding them in order.
+  Stream<List<int>> _readAhead(String sourceId, int start, int end) async* {
+    final pending = Queue<Future<List<int>>>();
+    var next = start;
+    void fill() {
+      while (pending.length < readAheadChunks && next <= end) {
+        final chunkEnd = math.min(next + readAheadChunkBytes - 1, end);
+        // Errors surface when the chunk is awaited; ignore() only keeps a
+        // chunk that fails early from being reported as unhandled.
+        pending.add(_readChunk(sourceId, next, chunkEnd)..ignore());
+        next = chunkEnd + 1;
+      }
+    }
+
+    try {
+      fill();
+      while (pending.isNotEmpty) {
+        final chunk = await pending.removeFirst();
+        fill();
+        yield chunk;
+      }
+    } finally {
+      // The player dropped the connection (e.g. a seek); discard prefetches.
+      pending.clear();
+    }
+  }
+
+  Future<List<int>> _readChunk(String sourceId, int start, int end) async {
+    final builder = BytesBuilder(copy: false);
+    await for (final data in await _source.read(sourceId, start, end)) {
+      builder.add(data);
+    }
+    return builder.takeBytes();
+  }
+
   Response _handleMemory(Request request, String token, Uint8List data) {
     try {
       final total = data.length;
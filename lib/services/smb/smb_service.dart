@@ -1,6 +1,12 @@
+import 'dart:async';
+import 'dart:io';
+
+import 'package:flutter/foundation.dart' show visibleForTesting;
 import 'package:smb_connect/smb_connect.dart';
 
 import '../streaming/range_source.dart';
+import 'netbios_name_resolver.dart';
+import 'smb_path.dart';
 
 /// SMB/CIFS connection parameters. Port is fixed to the SMB default (445)
 /// because smb_connect 0.0.9 does not expose a custom port.
@@ -18,30 +24,250 @@ class SmbConfig {
   final String domain;
 }
 
+/// What the current login may do with one SMB path, as reported by
+/// [SmbService.checkAccess]. Lets the UI flag read-only shares up front
+/// instead of failing mid-operation.
+class SmbAccessReport {
+  const SmbAccessReport({
+    required this.path,
+    required this.exists,
+    required this.canRead,
+    required this.canList,
+    required this.canWrite,
+    this.leftoverProbe,
+  });
+
+  final String path;
+  final bool exists;
+  final bool canRead;
+
+  /// Only meaningful for folders; always false for files.
+  final bool canList;
+  final bool canWrite;
+
+  /// The write-probe marker when it was written but couldn't be deleted
+  /// again; it stays on the share until someone removes it.
+  final String? leftoverProbe;
+
+  bool get isReadOnly => canRead && !canWrite;
+}
+
+/// Where the time went in the last [SmbService.connect], for telling a slow
+/// name lookup from a slow NAS. smb_connect connects, negotiates and
+/// authenticates in one call, so those phases are reported together.
+class SmbConnectTimings {
+  const SmbConnectTimings({required this.resolve, required this.session});
+
+  /// NetBIOS fallback lookup; zero for IPs and names the OS resolves, whose
+  /// DNS time is part of [session].
+  final Duration resolve;
+
+  /// TCP connect, dialect negotiation, and authentication.
+  final Duration session;
+
+  Duration get total => resolve + session;
+
+  Map<String, int> toJson() => {
+    'resolveMs': resolve.inMilliseconds,
+    'sessionMs': session.inMilliseconds,
+    'totalMs': total.inMilliseconds,
+  };
+}
+
+/// Why the server refused a login, from the NT status in the auth error.
+enum SmbLoginProblem {
+  lockedOut('账户已被 NAS 锁定，请稍后再试或联系管理员解锁', [
+    'currently locked out',
+    'account_locked_out',
+  ]),
+  passwordExpired('账户密码已过期，请先在 NAS 上修改密码', [
+    'password has expired',
+    'password_expired',
+  ]),
+  accountDisabled('账户已被 NAS 停用', [
+    'account currently disabled',
+    'account_disabled',
+  ]),
+  badCredentials('用户名或密码错误', [
+    'unknown user name or bad password',
+    'network password is not correct',
+    'logon_failure',
+  ]);
+
+  const SmbLoginProblem(this.message, this.markers);
+
+  final String message;
+
+  /// Lower-cased fragments of the server's status text or name.
+  final List<String> markers;
+}
+
 /// Wraps a single [SmbConnect] session: share/dir browsing plus the ranged
 /// reads the proxy needs. Implements [RangeSource] so the proxy can stay
 /// storage-agnostic.
 class SmbService implements RangeSource {
+  SmbService({this.nameResolver = const NetbiosNameResolver()});
+
+  /// Finds bare NetBIOS names (`MYNAS`) that DNS doesn't know about.
+  final NetbiosNameResolver nameResolver;
+
   SmbConnect? _connect;
   SmbConfig? _config;
 
+  /// FILE_ATTRIBUTE_OFFLINE: the data lives on a cloud/archive tier, so the
+  /// first read may block while the NAS recalls it.
+  static const attrOffline = 0x1000;
+
+  /// Whether listings keep entries flagged hidden/system and dot-files. Off by
+  /// default so `.DS_Store`, `Thumbs.db` and friends don't clutter browsing.
+  bool showHidden = false;
+
+  /// How many times a dropped session (NAS reboot, idle expiry, reset
+  /// socket) was transparently re-established; shown beside the connect time
+  /// in the SMB browser.
+  int get reconnectCount => _reconnectCount;
+  int _reconnectCount = 0;
+
+  /// The reconnect every caller that saw the session drop waits on, so
+  /// concurrent failures don't each tear down the session another just built.
+  Future<void>? _reconnecting;
+
+  /// Bumped after each reconnect; a failure on an older session retries on
+  /// the new one instead of reconnecting again.
+  int _sessionGeneration = 0;
+
   bool get isConnected => _connect != null;
   SmbConfig? get config => _config;
 
+  /// Rejected logins allowed per host within [loginFailureWindow] before
+  /// further attempts are refused locally. NAS lockout policies commonly
+  /// trigger at five, so retries and [connectWithAny] stay below that.
+  static const maxLoginFailures = 3;
+  static const loginFailureWindow = Duration(minutes: 5);
+
+  final _loginFailures = <String, List<DateTime>>{};
+
+  /// Timings of the last successful [connect]; null before the first one.
+  SmbConnectTimings? get lastConnectTimings => _lastConnectTimings;
+  SmbConnectTimings? _lastConnectTimings;
+
   Future<void> connect(SmbConfig config) async {
+    final host = config.host.toLowerCase();
+    final now = DateTime.now();
+    final failures = (_loginFailures[host] ?? [])
+      ..removeWhere((at) => now.difference(at) > loginFailureWindow);
+    if (failures.length >= maxLoginFailures) {
+      throw StateError(
+        '${config.host} 登录失败次数过多，为避免 NAS 锁定账户，'
+        '请 ${loginFailureWindow.inMinutes} 分钟后再试',
+      );
+    }
+    // Only once a login will really be attempted: a refused retry leaves the
+    // current session alone.
     await disconnect();
-    _connect = await SmbConnect.connectAuth(
-      host: config.host,
-      username: config.username,
-      password: config.password,
-      domain: config.domain,
+
+    final stopwatch = Stopwatch()..start();
+    var resolved = Duration.zero;
+    try {
+      // [config] keeps the name as typed; library paths are built from it.
+      final target = await nameResolver.resolve(config.host);
+      resolved = stopwatch.elapsed;
+      _connect = await openSession(target, config);
+    } catch (error) {
+      final problem = loginProblem(error);
+      if (problem == null) rethrow;
+      _loginFailures[host] = failures..add(now);
+      throw StateError(problem.message);
+    }
+    _loginFailures.remove(host);
+    _lastConnectTimings = SmbConnectTimings(
+      resolve: resolved,
+      session: stopwatch.elapsed - resolved,
     );
     _config = config;
   }
 
-  Future<List<SmbFile>> listShares() => _conn.listShares();
+  /// TCP connect, negotiate and authenticate against [target], the resolved
+  /// address for [config]'s host. Overridden in tests to stand in for a NAS.
+  @visibleForTesting
+  Future<SmbConnect> openSession(String target, SmbConfig config) =>
+      SmbConnect.connectAuth(
+        host: target,
+        username: config.username,
+        password: config.password,
+        domain: config.domain,
+      );
+
+  /// Tries each credential set in [candidates] in order and keeps the first
+  /// session that authenticates. Returns the config that succeeded so callers
+  /// can persist it; rethrows the last failure when none work.
+  Future<SmbConfig> connectWithAny(List<SmbConfig> candidates) async {
+    if (candidates.isEmpty) {
+      throw ArgumentError.value(candidates, 'candidates', 'must not be empty');
+    }
+    Object? lastError;
+    StackTrace? lastStack;
+    for (final candidate in candidates) {
+      try {
+        await connect(candidate);
+        return candidate;
+      } catch (e, st) {
+        lastError = e;
+        lastStack = st;
+        // Only a rejected password is worth another credential set. A locked
+        // or expired account won't log in with any password, and a host that
+        // is down or timing out would just fail once per candidate.
+        if (loginProblem(e) != SmbLoginProblem.badCredentials) break;
+      }
+    }
+    Error.throwWithStackTrace(lastError!, lastStack!);
+  }
+
+  Future<List<SmbFile>> listShares() async =>
+      visibleShares(await resilient(() => _conn.listShares()));
+
+  /// Drops the IPC and printer-driver pipes, which never hold files, and
+  /// unless [showHidden] is on, administrative `$` shares such as `ADMIN$`
+  /// and `C$`.
+  List<SmbFile> visibleShares(List<SmbFile> shares) {
+    return shares.where((share) {
+      final name = share.name.toUpperCase();
+      if (name == r'IPC$' || name == r'PRINT$') return false;
+      return showHidden || !name.endsWith(r'$');
+    }).toList();
+  }
+
+  Future<List<SmbFile>> listChildren(SmbFile folder) async =>
+      visibleEntries(await resilient(() => _conn.listFiles(folder)));
 
-  Future<List<SmbFile>> listChildren(SmbFile folder) => _conn.listFiles(folder);
+  /// Like [listChildren], but yields each directory-query response as it
+  /// arrives, so a folder with 100k+ entries is never held in memory whole.
+  /// The first page goes through [resilient], so a session that dropped
+  /// while idle is rebuilt and the listing restarted; once a page has been
+  /// yielded it can't be taken back, and later failures pass through.
+  Stream<List<SmbFile>> listChildrenPaged(SmbFile folder) async* {
+    late StreamIterator<List<SmbFile>> pages;
+    final hasFirst = await resilient(() async {
+      final attempt = StreamIterator(_conn.listFilesPaged(folder));
+      try {
+        final hasPage = await attempt.moveNext();
+        pages = attempt;
+        return hasPage;
+      } catch (_) {
+        await attempt.cancel();
+        rethrow;
+      }
+    });
+    try {
+      if (!hasFirst) return;
+      yield visibleEntries(pages.current);
+      while (await pages.moveNext()) {
+        yield visibleEntries(pages.current);
+      }
+    } finally {
+      await pages.cancel();
+    }
+  }
 
   /// Lists children of a folder by its server path.
   /// Useful as a fallback when a folder's SmbFile instance is missing the
@@ -49,7 +275,24 @@ class SmbService implements RangeSource {
   /// normal traversal.
   Future<List<SmbFile>> listChildrenByPath(String path) async {
     final folder = await openFolder(path);
-    return _conn.listFiles(folder);
+    return visibleEntries(await resilient(() => _conn.listFiles(folder)));
+  }
+
+  /// Applies the [showHidden] policy to a raw directory listing.
+  List<SmbFile> visibleEntries(List<SmbFile> entries) {
+    if (showHidden) return entries;
+    return entries.where((entry) => !isHiddenEntry(entry)).toList();
+  }
+
+  /// True for entries the server flags hidden or system, plus Unix-style
+  /// dot-files that Samba doesn't always map to the hidden attribute.
+  static bool isHiddenEntry(SmbFile file) {
+    return file.isHidden() || file.isSystem() || file.name.startsWith('.');
+  }
+
+  /// True when the entry is tiered offline (e.g. cloud-synced share).
+  static bool isOfflineEntry(SmbFile file) {
+    return (file.attributes & attrOffline) != 0;
   }
 
   /// Opens a folder by its server path (e.g. `/Media` or `/Media/Movies`).
@@ -57,18 +300,141 @@ class SmbService implements RangeSource {
   /// Used to browse directly into a known share when share enumeration via
   /// srvsvc isn't available on the server (common on some NAS configs).
   Future<SmbFile> openFolder(String path) {
-    var cleanPath = path;
-    if (cleanPath.startsWith('smb://')) {
+    final normalized = SmbPath.normalize(path).serverPath;
+    return resilient(() => _conn.file(normalized));
+  }
+
+  /// Returns [path] with each component spelled as it is on the server,
+  /// matching names case-insensitively where the exact name is missing. Paths
+  /// that came from metadata or another client often differ only in case,
+  /// which case-sensitive Samba shares reject. Null when some component
+  /// doesn't exist under any casing.
+  Future<String?> resolveCasing(String path) async {
+    final target = SmbPath.normalize(path);
+    if (target.isRoot || await _exists(target.serverPath)) {
+      return target.serverPath;
+    }
+
+    try {
+      var resolved = SmbPath.root;
+      for (final wanted in target.segments) {
+        final Iterable<String> candidates;
+        if (!resolved.isRoot) {
+          final children = await listChildrenByPath(resolved.serverPath);
+          candidates = children.map((entry) => entry.name);
+        } else if (await _exists('/$wanted')) {
+          candidates = [wanted];
+        } else {
+          candidates = (await listShares()).map((share) => share.name);
+        }
+        final actual = _matchName(wanted, candidates);
+        if (actual == null) return null;
+        resolved = resolved.join(actual);
+      }
+      return resolved.serverPath;
+    } catch (_) {
+      return null;
+    }
+  }
+
+  Future<bool> _exists(String path) async {
+    try {
+      return (await openFolder(path)).isExists;
+    } catch (_) {
+      return false;
+    }
+  }
+
+  static String? _matchName(String wanted, Iterable<String> names) {
+    final lower = wanted.toLowerCase();
+    String? folded;
+    for (final name in names) {
+      if (name == wanted) return name;
+      if (folded == null && name.toLowerCase() == lower) folded = name;
+    }
+    return folded;
+  }
+
+  /// Probes read, list, and write access on [path] with the current login.
+  ///
+  /// The write probe creates and immediately deletes an empty
+  /// `.open-filmly-access-*` file in the folder (or the file's parent). The
+  /// dot only hides it from Unix clients; a Windows client listing the folder
+  /// at that instant may see it.
+  Future<SmbAccessReport> checkAccess(String path) async {
+    final target = await openFolder(path);
+    if (!target.isExists) {
+      return SmbAccessReport(
+        path: target.path,
+        exists: false,
+        canRead: false,
+        canList: false,
+        canWrite: false,
+      );
+    }
+
+    // Share roots opened via file() may lack the DIRECTORY flag.
+    final targetPath = SmbPath.normalize(target.path);
+    final isFolder = target.isDirectory() || targetPath.segments.length <= 1;
+    final canList = isFolder && await _probe(() => listChildren(target));
+    final canRead = isFolder
+        ? canList
+        : await _probe(() async {
+            final stream = await read(target.path, 0, 0);
+            await stream.drain<void>();
+          });
+    final folder = isFolder ? targetPath : targetPath.parent;
+    String? leftoverProbe;
+    final canWrite =
+        !target.isReadonly() &&
+        await _probe(
+          () async => leftoverProbe = await probeWrite(folder.serverPath),
+        );
+
+    return SmbAccessReport(
+      path: target.path,
+      exists: true,
+      canRead: canRead,
+      canList: canList,
+      canWrite: canWrite,
+      leftoverProbe: leftoverProbe,
+    );
+  }
+
+  /// Creates and deletes a marker file in [folderPath]; throws when the
+  /// login may not write there. Returns the marker's path when it was
+  /// written but still couldn't be deleted after a retry, null otherwise.
+  Future<String?> probeWrite(String folderPath) async {
+    final marker = SmbPath.normalize(folderPath)
+        .join('.open-filmly-access-${DateTime.now().microsecondsSinceEpoch}')
+        .serverPath;
+    // Retried separately so a dropped session during the delete doesn't
+    // leave a second marker behind.
+    final probe = await resilient(() => _conn.createFile(marker));
+    for (var attempt = 1; ; attempt++) {
       try {
-        final uri = Uri.parse(cleanPath);
-        cleanPath = uri.path;
+        await resilient(() => _conn.delete(probe));
+        return null;
       } catch (_) {
-        final stripped = cleanPath.replaceFirst(RegExp(r'^smb://[^/]+'), '');
-        cleanPath = stripped.isEmpty ? '/' : stripped;
+        // Writing worked, which is what was asked; the marker is reported.
+        if (attempt >= probeDeleteAttempts) return probe.path;
+        await Future<void>.delayed(probeDeleteRetryDelay);
       }
     }
-    final normalized = cleanPath.startsWith('/') ? cleanPath : '/$cleanPath';
-    return _conn.file(normalized);
+  }
+
+  /// How often [probeWrite] tries to delete its marker, and how long it
+  /// waits in between.
+  static const probeDeleteAttempts = 2;
+  static const probeDeleteRetryDelay = Duration(milliseconds: 500);
+
+  Future<bool> _probe(Future<void> Function() action) async {
+    try {
+      await action();
+      return true;
+    } catch (_) {
+      return false;
+    }
   }
 
   /// Common NAS share names probed when srvsvc enumeration is unavailable,
@@ -147,7 +513,7 @@ class SmbService implements RangeSource {
 
   @override
   Future<int> length(String path) async {
-    final file = await _conn.file(path);
+    final file = await resilient(() => _conn.file(path));
     return file.size;
   }
 
@@ -158,9 +524,101 @@ class SmbService implements RangeSource {
     int start,
     int endInclusive,
   ) async {
-    final file = await _conn.file(path);
-    final stream = await _conn.openRead(file, start, endInclusive + 1);
-    return stream;
+    return resilient(() async {
+      final file = await _conn.file(path);
+      return _conn.openRead(file, start, endInclusive + 1);
+    });
+  }
+
+  /// Delays before each retry of a file another client holds open, e.g. a
+  /// download client still writing it. Bounded so a file locked for good
+  /// still fails within a couple of seconds.
+  static const sharingViolationBackoff = [
+    Duration(milliseconds: 200),
+    Duration(milliseconds: 600),
+    Duration(milliseconds: 1500),
+  ];
+
+  /// Runs [action]; if it fails because the session is gone, reconnects with
+  /// the last config and retries exactly once. Concurrent failures share a
+  /// single reconnect. A sharing violation is retried after each
+  /// [sharingViolationBackoff] delay. Other errors pass through.
+  @visibleForTesting
+  Future<T> resilient<T>(Future<T> Function() action) async {
+    for (var attempt = 0; ; attempt++) {
+      final generation = _sessionGeneration;
+      try {
+        return await action();
+      } catch (error) {
+        if (isSharingViolation(error) &&
+            attempt < sharingViolationBackoff.length) {
+          await Future<void>.delayed(sharingViolationBackoff[attempt]);
+          continue;
+        }
+        if (!isSessionLost(error)) rethrow;
+        if (generation == _sessionGeneration) {
+          final config = this.config;
+          if (_reconnecting == null && config == null) rethrow;
+          await (_reconnecting ??= _reconnect(config!));
+        }
+        return action();
+      }
+    }
+  }
+
+  Future<void> _reconnect(SmbConfig config) async {
+    try {
+      await connect(config);
+      _reconnectCount++;
+      _sessionGeneration++;
+    } finally {
+      _reconnecting = null;
+    }
+  }
+
+  /// True when the file is open elsewhere with a share mode that excludes
+  /// us; usually transient while another client finishes writing.
+  static bool isSharingViolation(Object error) {
+    final text = error.toString().toUpperCase();
+    return text.contains('SHARING_VIOLATION') ||
+        text.contains('C0000043') ||
+        text.contains('BEING USED BY ANOTHER PROCESS');
+  }
+
+  /// Classifies a rejected login from the NT status text smb_connect puts in
+  /// its auth errors (or the Chinese message [connect] rethrows them as);
+  /// null when [error] isn't a login refusal.
+  static SmbLoginProblem? loginProblem(Object error) {
+    final text = error.toString();
+    final lower = text.toLowerCase();
+    for (final problem in SmbLoginProblem.values) {
+      if (text.contains(problem.message)) return problem;
+      if (problem.markers.any(lower.contains)) return problem;
+    }
+    return null;
+  }
+
+  /// True for failures that mean the SMB session itself died rather than the
+  /// operation being refused: expired/deleted sessions and dropped sockets.
+  /// smb_connect doesn't export its exception types, so this matches on the
+  /// NT status (name, hex, or signed decimal) carried in the message.
+  static bool isSessionLost(Object error) {
+    if (error is SocketException) return true;
+    final text = error.toString().toUpperCase();
+    const markers = [
+      'NETWORK_SESSION_EXPIRED',
+      'C000035C',
+      '-1073740964',
+      'USER_SESSION_DELETED',
+      'C0000203',
+      '-1073741309',
+      'CONNECTION_RESET',
+      'CONNECTION RESET',
+      'BROKEN PIPE',
+      'TRANSPORT CLOSED',
+      'CANT SEND REQUEST',
+    ];
+    return markers.any(text.contains);
   }
 
   Future<void> disconnect() async {
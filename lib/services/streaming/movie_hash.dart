@@ -0,0 +1,37 @@
+import 'dart:typed_data';
+
+import 'range_source.dart';
+
+/// Computes the OpenSubtitles "moviehash" of [id]: the file size plus the
+/// sums of the first and last 64 KiB read as little-endian 64-bit words, all
+/// modulo 2^64, as 16 lowercase hex digits.
+///
+/// Only those two 64 KiB windows are read, so a remote file can be
+/// fingerprinted for subtitle lookup without downloading it. Throws
+/// [ArgumentError] for files smaller than one window, which the algorithm
+/// doesn't define.
+///
+/// Nothing calls this yet: the app has no online subtitle search, and this
+/// is the key such a lookup would send.
+Future<String> computeMovieHash(RangeSource source, String id) async {
+  const chunk = 64 * 1024;
+  final size = await source.length(id);
+  if (size < chunk) {
+    throw ArgumentError.value(size, 'size', 'file is smaller than 64 KiB');
+  }
+
+  var hash = BigInt.from(size);
+  for (final start in [0, size - chunk]) {
+    final bytes = BytesBuilder(copy: false);
+    await for (final data in await source.read(id, start, start + chunk - 1)) {
+      bytes.add(data);
+    }
+    final view = ByteData.sublistView(bytes.takeBytes());
+    for (var offset = 0; offset + 8 <= view.lengthInBytes; offset += 8) {
+      final low = view.getUint32(offset, Endian.little);
+      final high = view.getUint32(offset + 4, Endian.little);
+      hash += (BigInt.from(high) << 32) + BigInt.from(low);
+    }
+  }
+  return hash.toUnsigned(64).toRadixString(16).padLeft(16, '0');
+}
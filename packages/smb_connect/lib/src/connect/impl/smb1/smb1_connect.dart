@@ -219,8 +219,8 @@ class Smb1Connect extends SmbConnect {
   }
 
   @override
-  Future<List<SmbFile>> listFiles(SmbFile folder,
-      [String wildcard = "*"]) async {
+  Stream<List<SmbFile>> listFilesPaged(SmbFile folder,
+      [String wildcard = "*"]) async* {
     var tree = await shareTree(folder.share);
     int searchAttributes = SmbConstants.ATTR_DIRECTORY |
         SmbConstants.ATTR_HIDDEN |
@@ -228,17 +228,16 @@ class Smb1Connect extends SmbConnect {
     Smb1FilesEnumerator enumerator = Smb1FilesEnumerator(
         tree, transport, folder, wildcard, searchAttributes);
 
-    final List<SmbFile> res = [];
-
-    while (enumerator.canNext()) {
-      var items = await enumerator.next();
-      if (items != null) {
-        var nextFiles = mapFileEntries(folder, items);
-        res.addAll(nextFiles);
+    try {
+      while (enumerator.canNext()) {
+        var items = await enumerator.next();
+        if (items != null) {
+          yield mapFileEntries(folder, items);
+        }
       }
+    } finally {
+      await enumerator.close();
     }
-    await enumerator.close();
-    return res;
   }
 
   Future<(int, SmbFile)> _openFile(
@@ -252,8 +252,8 @@ class Smb2Connect extends SmbConnect {
   }
 
   @override
-  Future<List<SmbFile>> listFiles(SmbFile folder,
-      [String wildcard = "*"]) async {
+  Stream<List<SmbFile>> listFilesPaged(SmbFile folder,
+      [String wildcard = "*"]) async* {
     var tree = await shareTree(folder.share);
     int searchAttributes = SmbConstants.ATTR_DIRECTORY |
         SmbConstants.ATTR_HIDDEN |
@@ -261,15 +261,16 @@ class Smb2Connect extends SmbConnect {
 
     Smb2FilesEnumerator enumerator = Smb2FilesEnumerator(
         tree, transport, folder, wildcard, searchAttributes);
-    final List<SmbFile> res = [];
-    while (enumerator.canNext()) {
-      final entries = await enumerator.next();
-      if (entries != null) {
-        res.addAll(mapFileEntries(folder, entries));
+    try {
+      while (enumerator.canNext()) {
+        final entries = await enumerator.next();
+        if (entries != null) {
+          yield mapFileEntries(folder, entries);
+        }
       }
+    } finally {
+      await enumerator.close();
     }
-    await enumerator.close();
-    return res;
   }
 
   Future<Smb2CreateResponse> openFile(SmbFile file,
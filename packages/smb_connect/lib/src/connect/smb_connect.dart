@@ -176,7 +176,19 @@ abstract class SmbConnect {
 
   Future<List<SmbFile>> listShares();
 
-  Future<List<SmbFile>> listFiles(SmbFile folder, [String wildcard = "*"]);
+  Future<List<SmbFile>> listFiles(SmbFile folder,
+      [String wildcard = "*"]) async {
+    final List<SmbFile> res = [];
+    await for (final page in listFilesPaged(folder, wildcard)) {
+      res.addAll(page);
+    }
+    return res;
+  }
+
+  /// Lists [folder] one server response at a time, so a directory with a very
+  /// large number of entries never has to be held in memory at once.
+  Stream<List<SmbFile>> listFilesPaged(SmbFile folder,
+      [String wildcard = "*"]);
 
   List<SmbFile> mapFileEntries(SmbFile folder, List<FileEntry> entries) {
     return entries.mapNotNull((e) {
@@ -170,4 +170,7 @@ class SmbException extends Error {
 
   SmbException.code(int code, String? message)
       : this(message ?? "Error code: $code");
+
+  @override
+  String toString() => message;
 }
@@ -59,6 +59,16 @@ void main() {
       expect(legacy.smbShare, 'share1');
       expect(legacy.tmdbApiKey, 'legacy-tmdb');
     });
+
+    test('round-trips the periodic scan interval', () {
+      const config = AppConfig(autoScanIntervalHours: 6);
+      expect(AppConfig.fromJson(config.toJson()).autoScanIntervalHours, 6);
+      expect(const AppConfig().autoScanIntervalHours, 0);
+      expect(
+        AppConfig.fromJson({'autoScanIntervalHours': -3}).autoScanIntervalHours,
+        0,
+      );
+    });
   });
 
   group('MediaRepository', () {
@@ -202,6 +212,113 @@ void main() {
       expect(movie.genres, ['Sci-Fi', 'Adventure']);
     });
 
+    test('findDuplicateMovies groups copies across sources', () async {
+      final repo = MediaRepository(db);
+      for (final media in const [
+        Media(
+          id: 'smb-dune',
+          title: 'Dune',
+          year: '2021',
+          type: MediaType.movie,
+          path: 'smb://nas/Media/Dune.2021.mkv',
+          detailsJson: '{"tmdbId":438631,"source":{"kind":"smb"}}',
+        ),
+        Media(
+          id: 'webdav-dune',
+          title: '沙丘',
+          year: '2021',
+          type: MediaType.movie,
+          path: 'https://dav/Dune.2021.mkv',
+          posterPath: 'https://image.tmdb.org/t/p/w500/dune.jpg',
+          detailsJson: '{"tmdbId":438631,"source":{"kind":"webdav"}}',
+        ),
+        Media(
+          id: 'arrival-a',
+          title: 'Arrival',
+          year: '2016',
+          type: MediaType.movie,
+          path: '/a/Arrival.mkv',
+        ),
+        Media(
+          id: 'arrival-b',
+          title: 'arrival',
+          year: '2016',
+          type: MediaType.movie,
+          path: '/b/Arrival.mkv',
+        ),
+        Media(
+          id: 'blade-runner',
+          title: 'Blade Runner',
+          year: '1982',
+          type: MediaType.movie,
+          path: '/a/Blade.Runner.mkv',
+        ),
+      ]) {
+        await repo.upsert(media);
+      }
+
+      final groups = await repo.findDuplicateMovies();
+
+      expect(groups, hasLength(2));
+      final dune = groups.firstWhere((g) => g.first.tmdbId == 438631);
+      expect(dune.map((m) => m.id), ['webdav-dune', 'smb-dune']);
+      final arrival = groups.firstWhere((g) => g.first.title == 'Arrival');
+      expect(arrival.map((m) => m.id), ['arrival-a', 'arrival-b']);
+    });
+
+    test('findDuplicateMovies keeps the better release on a tie', () async {
+      final repo = MediaRepository(db);
+      for (final media in const [
+        Media(
+          id: 'alien-a',
+          title: 'Alien',
+          year: '1979',
+          type: MediaType.movie,
+          path: '/a/Alien.1979.1080p.WEB-DL.x264-GRP.mkv',
+        ),
+        Media(
+          id: 'alien-b',
+          title: 'Alien',
+          year: '1979',
+          type: MediaType.movie,
+          path: '/b/Alien.1979.2160p.UHD.BluRay.REMUX.HDR.HEVC-FGT.mkv',
+        ),
+      ]) {
+        await repo.upsert(media);
+      }
+
+      final groups = await repo.findDuplicateMovies();
+
+      expect(groups.single.map((m) => m.id), ['alien-b', 'alien-a']);
+    });
+
+    test('search matches across traditional/simplified and accents', () async {
+      final repo = MediaRepository(db);
+      await repo.upsert(
+        const Media(
+          id: 'got',
+          title: '權力的遊戲',
+          year: '2011',
+          type: MediaType.tv,
+          path: '/tv/權力的遊戲',
+        ),
+      );
+      await repo.upsert(
+        const Media(
+          id: 'amelie',
+          title: 'Amélie',
+          year: '2001',
+          type: MediaType.movie,
+          path: '/movies/Amelie.2001.mkv',
+        ),
+      );
+
+      final got = await repo.browse(searchTerm: '权力的游戏');
+      expect(got.map((m) => m.id), ['got']);
+      final amelie = await repo.browse(searchTerm: 'AMÉLIE');
+      expect(amelie.map((m) => m.id), ['amelie']);
+    });
+
     test('browse filters search terms and sorts by rating/year', () async {
       final repo = MediaRepository(db);
       await repo.upsert(
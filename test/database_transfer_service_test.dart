@@ -1,3 +1,5 @@
+import 'dart:io';
+
 import 'package:drift/drift.dart';
 import 'package:drift/native.dart';
 import 'package:flutter_test/flutter_test.dart';
@@ -105,4 +107,91 @@ void main() {
       contains('"position":20'),
     );
   });
+
+  test('compact drops episodes whose show is gone', () async {
+    await target
+        .into(target.mediaItems)
+        .insert(
+          MediaItemsCompanion.insert(
+            id: 'show',
+            title: '漫长的季节',
+            type: 'tv',
+            path: '漫长的季节',
+            dateAdded: '2026-01-01',
+            lastUpdated: '2026-01-01',
+          ),
+        );
+    for (final showId in ['show', 'deleted-show']) {
+      await target
+          .into(target.episodes)
+          .insert(
+            EpisodesCompanion.insert(
+              id: '$showId-01',
+              showId: showId,
+              seasonNumber: 1,
+              episodeNumber: 1,
+              path: '01.mkv',
+              dateAdded: '2026-01-01',
+            ),
+          );
+    }
+
+    final removed = await DatabaseTransferService(target).compact();
+
+    expect(removed, 1);
+    final episodes = await target.select(target.episodes).get();
+    expect(episodes.map((row) => row.id), ['show-01']);
+  });
+
+  test('refuses snapshots from a newer schema version', () async {
+    final dir = await Directory.systemTemp.createTemp('transfer-test');
+    addTearDown(() => dir.delete(recursive: true));
+    final file = File('${dir.path}/future.sqlite');
+    final future = AppDatabase(NativeDatabase(file));
+    await future.customStatement('PRAGMA user_version = 99');
+    await future.close();
+
+    await expectLater(
+      DatabaseTransferService(target).importFromPath(file.path),
+      throwsStateError,
+    );
+  });
+
+  test('upgrades an older snapshot on a copy before merging', () async {
+    final dir = await Directory.systemTemp.createTemp('transfer-test');
+    addTearDown(() => dir.delete(recursive: true));
+    final file = File('${dir.path}/old.sqlite');
+    final old = AppDatabase(NativeDatabase(file));
+    await old
+        .into(old.mediaItems)
+        .insert(
+          MediaItemsCompanion.insert(
+            id: 'movie-1',
+            title: 'Dune',
+            type: 'movie',
+            path: '/Movies/Dune.mkv',
+            dateAdded: '2026-01-01',
+            lastUpdated: '2026-01-01',
+          ),
+        );
+    // Roll the snapshot back to v2: no favorites column yet.
+    await old.customStatement('ALTER TABLE media DROP COLUMN is_favorite');
+    await old.customStatement('PRAGMA user_version = 2');
+    await old.close();
+
+    final result = await DatabaseTransferService(
+      target,
+    ).importFromPath(file.path);
+
+    expect(result.mediaRows, 1);
+    final copied = await target.select(target.mediaItems).getSingle();
+    expect(copied.isFavorite, isFalse);
+    // The user's file was not migrated in place.
+    final reopened = AppDatabase(NativeDatabase(file, enableMigrations: false));
+    final version = await reopened
+        .customSelect('PRAGMA user_version')
+        .getSingle();
+    await reopened.close();
+    expect(version.read<int>('user_version'), 2);
+  });
 }
@@ -0,0 +1,142 @@
+import 'package:drift/native.dart';
+import 'package:flutter_test/flutter_test.dart';
+import 'package:http/http.dart' as http;
+import 'package:open_filmly/data/database/database.dart';
+import 'package:open_filmly/data/models/media.dart';
+import 'package:open_filmly/data/repositories/episode_repository.dart';
+import 'package:open_filmly/data/repositories/media_repository.dart';
+import 'package:open_filmly/services/dlna/dlna_service.dart';
+import 'package:open_filmly/services/library/dlna_library_import_service.dart';
+
+class _FakeDlnaService extends DlnaService {
+  _FakeDlnaService(this.tree) : super(http.Client());
+
+  final Map<String, List<DlnaEntry>> tree;
+
+  @override
+  Future<List<DlnaEntry>> browse(
+    DlnaServer server, {
+    String objectId = '0',
+  }) async => tree[objectId] ?? const [];
+}
+
+void main() {
+  test('reads LOCATION from an SSDP response', () {
+    const response =
+        'HTTP/1.1 200 OK\r\n'
+        'CACHE-CONTROL: max-age=1800\r\n'
+        'Location: http://192.168.1.20:50001/desc/device.xml\r\n'
+        'ST: urn:schemas-upnp-org:device:MediaServer:1\r\n'
+        '\r\n';
+
+    expect(
+      DlnaService.parseSsdpLocation(response),
+      Uri.parse('http://192.168.1.20:50001/desc/device.xml'),
+    );
+  });
+
+  test('resolves the ContentDirectory control URL', () {
+    const xml = '''
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <friendlyName>DS920 Media Server</friendlyName>
+    <serviceList>
+      <service>
+        <serviceType>urn:schemas-upnp-org:service:ConnectionManager:1</serviceType>
+        <controlURL>/cm/control</controlURL>
+      </service>
+      <service>
+        <serviceType>urn:schemas-upnp-org:service:ContentDirectory:1</serviceType>
+        <controlURL>/cd/control</controlURL>
+      </service>
+    </serviceList>
+  </device>
+</root>''';
+
+    final server = DlnaService.parseDescription(
+      xml,
+      Uri.parse('http://192.168.1.20:50001/desc/device.xml'),
+    );
+
+    expect(server!.name, 'DS920 Media Server');
+    expect(
+      server.controlUrl,
+      Uri.parse('http://192.168.1.20:50001/cd/control'),
+    );
+  });
+
+  test('parses containers and items from a Browse response', () {
+    const soap = r'''
+<s:Envelope><s:Body><u:BrowseResponse>
+<Result>&lt;DIDL-Lite&gt;&lt;container id="64$1" parentID="64" childCount="3"&gt;&lt;dc:title&gt;Movies&lt;/dc:title&gt;&lt;/container&gt;&lt;item id="64$2" parentID="64"&gt;&lt;dc:title&gt;Dune &amp;amp; Co&lt;/dc:title&gt;&lt;res size="2048" protocolInfo="http-get:*:video/x-matroska:*"&gt;http://192.168.1.20:50002/v/Dune.mkv&lt;/res&gt;&lt;/item&gt;&lt;/DIDL-Lite&gt;</Result>
+<NumberReturned>2</NumberReturned>
+</u:BrowseResponse></s:Body></s:Envelope>''';
+
+    final entries = DlnaService.parseBrowseResponse(soap);
+
+    expect(entries, hasLength(2));
+    expect(entries.first.isContainer, isTrue);
+    expect(entries.first.title, 'Movies');
+    expect(entries.last.id, r'64$2');
+    expect(entries.last.title, 'Dune & Co');
+    expect(entries.last.size, 2048);
+    expect(
+      entries.last.resourceUrl,
+      Uri.parse('http://192.168.1.20:50002/v/Dune.mkv'),
+    );
+  });
+  test('tells video items apart from music and photos', () {
+    const soap = r'''
+<s:Envelope><s:Body><u:BrowseResponse>
+<Result>&lt;DIDL-Lite&gt;&lt;item id="1"&gt;&lt;dc:title&gt;Dune&lt;/dc:title&gt;&lt;upnp:class&gt;object.item.videoItem.movie&lt;/upnp:class&gt;&lt;res&gt;http://nas/v/1.mkv&lt;/res&gt;&lt;/item&gt;&lt;item id="2"&gt;&lt;dc:title&gt;Song&lt;/dc:title&gt;&lt;upnp:class&gt;object.item.audioItem.musicTrack&lt;/upnp:class&gt;&lt;res&gt;http://nas/a/2.flac&lt;/res&gt;&lt;/item&gt;&lt;/DIDL-Lite&gt;</Result>
+</u:BrowseResponse></s:Body></s:Envelope>''';
+
+    final entries = DlnaService.parseBrowseResponse(soap);
+
+    expect(entries.first.upnpClass, 'object.item.videoItem.movie');
+    expect(entries.first.isVideo, isTrue);
+    expect(entries.last.isVideo, isFalse);
+  });
+
+  test('imports video items below a container', () async {
+    final db = AppDatabase(NativeDatabase.memory());
+    addTearDown(db.close);
+    final repo = MediaRepository(db);
+    final server = DlnaServer(
+      name: 'NAS',
+      location: Uri.parse('http://192.168.1.20:50001/desc/device.xml'),
+      controlUrl: Uri.parse('http://192.168.1.20:50001/cd/control'),
+    );
+    final dune = Uri.parse('http://192.168.1.20:50002/v/Dune.2021.mkv');
+    final dlna = _FakeDlnaService({
+      '0': const [DlnaEntry(id: 'm', title: 'Movies', isContainer: true)],
+      'm': [
+        DlnaEntry(
+          id: 'm1',
+          title: 'Dune (2021)',
+          isContainer: false,
+          resourceUrl: dune,
+          upnpClass: 'object.item.videoItem.movie',
+        ),
+        DlnaEntry(
+          id: 'm2',
+          title: 'Theme',
+          isContainer: false,
+          resourceUrl: Uri.parse('http://192.168.1.20:50002/a/theme.mp3'),
+          upnpClass: 'object.item.audioItem.musicTrack',
+        ),
+      ],
+    });
+
+    final result = await DlnaLibraryImportService(
+      dlna,
+      repo,
+      EpisodeRepository(db),
+    ).importContainer(server);
+
+    expect(result.importedItems, 1);
+    expect(result.movieCount, 1);
+    final movie = (await repo.getByType(MediaType.movie)).single;
+    expect(movie.path, dune.toString());
+  });
+}
@@ -4,6 +4,7 @@ import 'package:flutter_riverpod/flutter_riverpod.dart';
 import 'package:flutter_test/flutter_test.dart';
 import 'package:open_filmly/core/router/app_router.dart';
 import 'package:open_filmly/data/database/database.dart';
+import 'package:open_filmly/data/models/anime_release_name.dart';
 import 'package:open_filmly/data/models/episode.dart';
 import 'package:open_filmly/data/models/media.dart';
 import 'package:open_filmly/data/repositories/episode_repository.dart';
@@ -52,6 +53,39 @@ void main() {
       expect(entry.episode!.episodeNumber, 10);
     });
 
+    test('parses fansub releases with absolute numbering', () {
+      final entry = MediaLibraryEntryFactory.fromLocalPath(
+        '/downloads/[SubsPlease] Frieren/'
+        '[SubsPlease] Sousou no Frieren - 07v2 (1080p) [ABCD1234].mkv',
+      );
+
+      expect(entry.media.type, MediaType.tv);
+      expect(entry.media.title, 'Sousou no Frieren');
+      expect(entry.episode!.seasonNumber, 1);
+      expect(entry.episode!.episodeNumber, 7);
+    });
+
+    test('fansub season markers join the same show', () {
+      final s1 = MediaLibraryEntryFactory.fromLocalPath(
+        '/anime/[SubsPlease] Mushoku Tensei - 11 (1080p).mkv',
+      );
+      final s2 = MediaLibraryEntryFactory.fromLocalPath(
+        '/anime/[Nekomoe kissaten][Mushoku Tensei S2][03][1080p][CHS].mp4',
+      );
+
+      expect(s2.media.title, 'Mushoku Tensei');
+      expect(s2.media.id, s1.media.id);
+      expect(s2.episode!.seasonNumber, 2);
+      expect(s2.episode!.episodeNumber, 3);
+
+      final v2 = AnimeReleaseName.tryParse('[Group] Show - 05v2 [720p]');
+      expect(v2?.version, 2);
+      expect(
+        AnimeReleaseName.tryParse('[Group] Your Name [2016][1080p]'),
+        isNull,
+      );
+    });
+
     test('movie files do not produce episodes', () {
       final entry = MediaLibraryEntryFactory.fromLocalPath(
         '/movies/Inception.2010.1080p.BluRay.mkv',
@@ -403,6 +437,27 @@ void main() {
       expect(seasons[1].episodes.length, 1);
     });
 
+    test('reports missing episodes per season', () {
+      Episode episode(int season, int number) => Episode(
+        id: 's${season}e$number',
+        showId: 'tv:gaps',
+        seasonNumber: season,
+        episodeNumber: number,
+        path: '/tv/Gaps/S${season}E$number.mkv',
+      );
+      final season = Season(
+        number: 2,
+        episodes: [episode(2, 1), episode(2, 2), episode(2, 4), episode(2, 6)],
+      );
+
+      expect(season.missingEpisodes(), [3, 5]);
+      expect(season.missingEpisodes(8), [3, 5, 7, 8]);
+      expect(
+        Season(number: 0, episodes: [episode(0, 5)]).missingEpisodes(),
+        isEmpty,
+      );
+    });
+
     test('countByShow returns total episode count', () async {
       const showId = 'tv:test-show';
       await mediaRepo.upsert(
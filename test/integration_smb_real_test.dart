@@ -1,61 +1,84 @@
+import 'dart:io';
+
 import 'package:test/test.dart';
 import 'package:smb_connect/smb_connect.dart';
 
+/// Runs against a real SMB server, e.g. a throwaway Samba container:
+///
+///     docker run -p 445:445 dperson/samba -u "filmly;filmly" \
+///         -s "media;/share;yes;no;no;filmly"
+///     FILMLY_SMB_E2E_HOST=127.0.0.1 FILMLY_SMB_E2E_USER=filmly \
+///         FILMLY_SMB_E2E_PASSWORD=filmly FILMLY_SMB_E2E_SHARE=media \
+///         flutter test test/integration_smb_real_test.dart
 void main() {
-  test('Real SMB Connection and List Files Closed Loop', () async {
-    print('Starting automated SMB connection test...');
-
-    // Connect to the real NAS
-    final conn = await SmbConnect.connectAuth(
-      host: '192.168.31.252',
-      username: 'xiaoyu',
-      password: '0206a0216cy',
-      domain: '',
-    );
-    print('Connected successfully!');
-
-    // 1. Get the list of shares
-    final shares = await conn.listShares();
-    expect(shares, isNotEmpty, reason: 'Expected to find at least one share');
-    print('Found shares: ${shares.map((e) => e.name).toList()}');
-
-    // 2. Open the wd share
-    final wdShare = shares.firstWhere((s) => s.name == 'wd');
-    expect(wdShare.name, 'wd');
-
-    final rootFolder = await conn.file(wdShare.path);
-    expect(
-      rootFolder.isExists,
-      isTrue,
-      reason: 'Root folder of wd share should exist',
-    );
-    print('Opened wd share root: path=${rootFolder.path}');
-
-    // 3. List the children of wd share
-    final children = await conn.listFiles(rootFolder);
-    expect(
-      children,
-      isNotEmpty,
-      reason: 'Expected wd share to have directories/files inside',
-    );
-
-    print('Found ${children.length} children in wd share.');
-    for (var c in children.take(5)) {
-      print(' - ${c.name} (isDir: ${c.isDirectory()})');
-    }
-
-    // 4. Try to list the first directory child (Deep Browse test)
-    final firstDir = children.firstWhere(
-      (c) => c.isDirectory(),
-      orElse: () => children.first,
-    );
-    if (firstDir.isDirectory()) {
-      print('Deep browsing into: ${firstDir.path}');
-      final subChildren = await conn.listFiles(firstDir);
-      print('Found ${subChildren.length} children inside ${firstDir.name}');
-      expect(subChildren, isNotNull);
-    }
-
-    print('Automated testing closed loop completed successfully!');
-  });
+  final env = Platform.environment;
+  final host = env['FILMLY_SMB_E2E_HOST'] ?? '';
+  final shareName = env['FILMLY_SMB_E2E_SHARE'] ?? '';
+  final missing = <String>[
+    if (host.isEmpty) 'FILMLY_SMB_E2E_HOST',
+    if (shareName.isEmpty) 'FILMLY_SMB_E2E_SHARE',
+  ];
+
+  test(
+    'Real SMB Connection and List Files Closed Loop',
+    () async {
+      print('Starting automated SMB connection test...');
+
+      final conn = await SmbConnect.connectAuth(
+        host: host,
+        username: env['FILMLY_SMB_E2E_USER'] ?? 'guest',
+        password: env['FILMLY_SMB_E2E_PASSWORD'] ?? '',
+        domain: env['FILMLY_SMB_E2E_DOMAIN'] ?? '',
+      );
+      print('Connected successfully!');
+
+      // 1. Get the list of shares
+      final shares = await conn.listShares();
+      expect(shares, isNotEmpty, reason: 'Expected to find at least one share');
+      print('Found shares: ${shares.map((e) => e.name).toList()}');
+
+      // 2. Open the configured share
+      final share = shares.firstWhere((s) => s.name == shareName);
+      expect(share.name, shareName);
+
+      final rootFolder = await conn.file(share.path);
+      expect(
+        rootFolder.isExists,
+        isTrue,
+        reason: 'Root folder of $shareName share should exist',
+      );
+      print('Opened $shareName share root: path=${rootFolder.path}');
+
+      // 3. List the children of the share
+      final children = await conn.listFiles(rootFolder);
+      expect(
+        children,
+        isNotEmpty,
+        reason: 'Expected $shareName share to have directories/files inside',
+      );
+
+      print('Found ${children.length} children in $shareName share.');
+      for (var c in children.take(5)) {
+        print(' - ${c.name} (isDir: ${c.isDirectory()})');
+      }
+
+      // 4. Try to list the first directory child (Deep Browse test)
+      final firstDir = children.firstWhere(
+        (c) => c.isDirectory(),
+        orElse: () => children.first,
+      );
+      if (firstDir.isDirectory()) {
+        print('Deep browsing into: ${firstDir.path}');
+        final subChildren = await conn.listFiles(firstDir);
+        print('Found ${subChildren.length} children inside ${firstDir.name}');
+        expect(subChildren, isNotNull);
+      }
+
+      print('Automated testing closed loop completed successfully!');
+    },
+    skip: missing.isEmpty
+        ? null
+        : 'Set FILMLY_SMB_E2E_HOST and FILMLY_SMB_E2E_SHARE to run: '
+              '${missing.join(', ')}',
+  );
 }
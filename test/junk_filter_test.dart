@@ -1,4 +1,5 @@
 import 'package:flutter_test/flutter_test.dart';
+import 'package:open_filmly/services/library/extras_classifier.dart';
 import 'package:open_filmly/services/library/media_library_entry_factory.dart';
 
 void main() {
@@ -36,4 +37,152 @@ void main() {
       expect(e.media.year, '1973');
     });
   });
+
+  group('NAS housekeeping directories', () {
+    test('are excluded case-insensitively', () {
+      const names = ['@eaDir', '#recycle', '\$RECYCLE.BIN', '.@__thumb'];
+      for (final name in names) {
+        expect(
+          MediaLibraryEntryFactory.isExcludedDirectoryName(name),
+          isTrue,
+          reason: name,
+        );
+      }
+      expect(
+        MediaLibraryEntryFactory.isExcludedDirectoryName('Movies'),
+        isFalse,
+      );
+    });
+
+    test('files beneath them are junk', () {
+      expect(
+        MediaLibraryEntryFactory.isImportableVideo(
+          '/volume1/video/#recycle/Dune.2021.mkv',
+        ),
+        isFalse,
+      );
+      expect(
+        MediaLibraryEntryFactory.isImportableVideo(
+          '/share/Movies/.@__thumb/Dune.2021.mkv',
+        ),
+        isFalse,
+      );
+    });
+
+    test('a custom exclusion set also applies to files', () {
+      const p = '/share/Movies/Staging/Dune.2021.mkv';
+      const excluded = {'staging'};
+      expect(
+        MediaLibraryEntryFactory.isImportableVideo(
+          p,
+          const ExtrasClassifier(),
+          excluded,
+        ),
+        isFalse,
+      );
+      expect(MediaLibraryEntryFactory.isImportableVideo(p), isTrue);
+    });
+
+    test('only folders below the scanned root count', () {
+      const root = '/volume1/Extras';
+      const movie = '$root/Dune.2021.mkv';
+      expect(MediaLibraryEntryFactory.isImportableVideo(movie), isFalse);
+      expect(
+        MediaLibraryEntryFactory.isImportableVideo(
+          movie,
+          const ExtrasClassifier(),
+          MediaLibraryEntryFactory.defaultExcludedDirectoryNames,
+          root,
+        ),
+        isTrue,
+      );
+      expect(
+        MediaLibraryEntryFactory.isImportableVideo(
+          '/#recycle/Movies/Dune.2021.mkv',
+          const ExtrasClassifier(),
+          MediaLibraryEntryFactory.defaultExcludedDirectoryNames,
+          '/#recycle/Movies',
+        ),
+        isTrue,
+      );
+    });
+  });
+
+  group('ExtrasClassifier', () {
+    const extras = ExtrasClassifier();
+
+    test('reports the rule and kind for Plex-style extras', () {
+      final suffix = extras.classify(
+        '/m/Dune (2021)/Dune (2021)-featurette.mkv',
+      );
+      expect(suffix?.kind, ExtraKind.featurette);
+      expect(suffix?.rule, 'plex-suffix');
+
+      final folder = extras.classify('/m/Dune (2021)/Behind The Scenes/a.mkv');
+      expect(folder?.kind, ExtraKind.behindTheScenes);
+      expect(folder?.rule, 'extras-folder');
+      expect(
+        MediaLibraryEntryFactory.isImportableVideo(
+          '/m/Dune (2021)/Featurettes/Making Arrakis.mkv',
+        ),
+        isFalse,
+      );
+    });
+
+    test('only reports a loose trailer word without skipping', () {
+      const p = '/m/Trailer.Park.Boys.S01E01.mkv';
+      final match = extras.classify(p);
+      expect(match?.rule, 'trailer-word');
+      expect(match!.confidence, lessThan(ExtrasClassifier.skipThreshold));
+      expect(MediaLibraryEntryFactory.isImportableVideo(p), isTrue);
+    });
+
+    test('a show named Extras is not mistaken for an extras folder', () {
+      const episode = '/TV/Extras/Extras.S01E01.mkv';
+      expect(extras.classify(episode), isNull);
+      expect(MediaLibraryEntryFactory.isImportableVideo(episode), isTrue);
+      expect(
+        extras.classify('/m/Dune (2021)/Extras/Interview.mkv')?.rule,
+        'extras-folder',
+      );
+    });
+
+    test('user patterns skip matches and ignore invalid regexes', () {
+      final custom = ExtrasClassifier.withPatterns(['/bonus disc/', '(']);
+      const p = '/m/Alien/Bonus Disc/Commentary.mkv';
+      expect(custom.classify(p)?.rule, 'custom:/bonus disc/');
+      expect(MediaLibraryEntryFactory.isImportableVideo(p, custom), isFalse);
+      expect(MediaLibraryEntryFactory.isImportableVideo(p), isTrue);
+    });
+  });
+
+  group('in-progress downloads', () {
+    test('are recognised by a marker beside the file', () {
+      expect(
+        MediaLibraryEntryFactory.isIncompleteDownload(
+          '/dl/Dune.2021.mkv',
+          siblingNames: {'dune.2021.mkv.aria2'},
+        ),
+        isTrue,
+      );
+      expect(
+        MediaLibraryEntryFactory.isIncompleteDownload(
+          '/dl/Dune.2021.mkv',
+          siblingNames: {'arrival.2016.mkv.part'},
+        ),
+        isFalse,
+      );
+      expect(MediaLibraryEntryFactory.isDownloadMarker('Dune.mkv.!qB'), isTrue);
+    });
+
+    test('a freshly copied file without a marker still imports', () {
+      expect(
+        MediaLibraryEntryFactory.isIncompleteDownload(
+          '/dl/Dune.2021.mkv',
+          siblingNames: {'dune.2021.mkv.nfo'},
+        ),
+        isFalse,
+      );
+    });
+  });
 }
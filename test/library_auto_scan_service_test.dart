@@ -8,9 +8,11 @@ import 'package:open_filmly/data/models/episode.dart';
 import 'package:open_filmly/data/models/media.dart';
 import 'package:open_filmly/data/repositories/episode_repository.dart';
 import 'package:open_filmly/data/repositories/media_repository.dart';
+import 'package:open_filmly/services/library/extras_classifier.dart';
 import 'package:open_filmly/services/library/library_auto_scan_service.dart';
 import 'package:open_filmly/services/library/library_metadata_sync_service.dart';
 import 'package:open_filmly/services/library/library_scanner_service.dart';
+import 'package:open_filmly/services/library/media_library_entry_factory.dart';
 import 'package:open_filmly/services/metadata/intelligent_name_recognizer.dart';
 import 'package:open_filmly/services/metadata/tmdb_metadata_service.dart';
 import 'package:http/http.dart' as http;
@@ -108,6 +110,39 @@ void main() {
       expect(await repo.getByType(MediaType.movie), isEmpty);
     });
 
+    test('purges junk by the scanner rules, below each source root', () async {
+      scanner = LibraryScannerService(
+        repo,
+        episodeRepo,
+        MediaLibraryEntryFactory.defaultExcludedDirectoryNames,
+        ExtrasClassifier.withPatterns(['/bonus/']),
+      );
+      final samples = path.join(tempDir.path, 'sample');
+      Media movie(String id, String fullPath) => Media(
+        id: id,
+        title: id,
+        year: '',
+        type: MediaType.movie,
+        path: fullPath,
+        fullPath: fullPath,
+      );
+      await repo.upsert(movie('share', 'smb://nas/Extras/Dune.2021.mkv'));
+      await repo.upsert(movie('local', path.join(samples, 'Heat.1995.mkv')));
+      await repo.upsert(
+        movie('bonus', 'smb://nas/Movies/Alien/Bonus/Commentary.mkv'),
+      );
+
+      await buildService().run(
+        AppConfig(selectedFolders: [samples], autoScanOnStartup: false),
+      );
+
+      final kept = await repo.getByType(MediaType.movie);
+      expect(
+        kept.map((media) => media.id),
+        unorderedEquals(['share', 'local']),
+      );
+    });
+
     test('retitles dirty legacy entries even when scan is skipped', () async {
       // Legacy SMB-imported entry with a dirty release-name title.
       await repo.upsert(
@@ -0,0 +1,109 @@
+import 'package:drift/native.dart';
+import 'package:flutter_test/flutter_test.dart';
+import 'package:open_filmly/data/database/database.dart';
+import 'package:open_filmly/data/models/episode.dart';
+import 'package:open_filmly/data/models/media.dart';
+import 'package:open_filmly/data/repositories/episode_repository.dart';
+import 'package:open_filmly/data/repositories/media_repository.dart';
+import 'package:open_filmly/services/library/library_export_service.dart';
+
+void main() {
+  late AppDatabase db;
+  late LibraryExportService export;
+
+  setUp(() async {
+    db = AppDatabase(NativeDatabase.memory());
+    final media = MediaRepository(db);
+    final episodes = EpisodeRepository(db);
+    export = LibraryExportService(media, episodes);
+
+    await media.upsert(
+      const Media(
+        id: 'smb://nas/Media/Movies/Dune, Part One.2021.mkv',
+        title: 'Dune, Part One',
+        year: '2021',
+        type: MediaType.movie,
+        path: 'smb://nas/Media/Movies/Dune, Part One.2021.mkv',
+      ),
+    );
+    await media.upsert(
+      const Media(
+        id: 'emby-1',
+        title: 'Arrival',
+        year: '2016',
+        type: MediaType.movie,
+        path: 'emby-item-1',
+      ),
+    );
+    await media.upsert(
+      const Media(
+        id: 'show',
+        title: '漫长的季节',
+        year: '2023',
+        type: MediaType.tv,
+        path: 'show',
+      ),
+    );
+    await episodes.upsert(
+      const Episode(
+        id: 'webdav|https://dav.example.com/dav|/TV/漫长的季节/E01.mkv',
+        showId: 'show',
+        seasonNumber: 1,
+        episodeNumber: 1,
+        path: 'webdav|https://dav.example.com/dav|/TV/漫长的季节/E01.mkv',
+      ),
+    );
+  });
+
+  tearDown(() => db.close());
+
+  test('M3U lists playable movies and episodes, skipping server ids', () async {
+    final m3u = LibraryExportService.toM3u(await export.collect());
+
+    expect(m3u, startsWith('#EXTM3U\n'));
+    expect(m3u, contains('#EXTINF:-1,Dune, Part One (2021)\n'));
+    expect(
+      m3u,
+      contains('smb://nas/Media/Movies/Dune%2C%20Part%20One.2021.mkv\n'),
+    );
+    expect(m3u, contains('#EXTINF:-1,漫长的季节 S01E01\n'));
+    expect(
+      m3u,
+      contains(
+        'https://dav.example.com/dav/TV/'
+        '%E6%BC%AB%E9%95%BF%E7%9A%84%E5%AD%A3%E8%8A%82/E01.mkv',
+      ),
+    );
+    expect(m3u, isNot(contains('Arrival')));
+  });
+
+  test('CSV quotes fields containing commas', () async {
+    final csv = LibraryExportService.toCsv(await export.collect());
+    final lines = csv.trim().split('\n');
+
+    expect(
+      lines.first,
+      'title,year,type,season,episode,episode_title,location',
+    );
+    expect(lines, contains(startsWith('"Dune, Part One",2021,movie,,,,')));
+    expect(lines, contains(startsWith('漫长的季节,2023,tv,1,1,,https://')));
+  });
+
+  test('locations encode # and ? in file names', () {
+    expect(
+      LibraryExportService.locationFor('smb://nas/Media/Se7en #1?.mkv'),
+      'smb://nas/Media/Se7en%20%231%3F.mkv',
+    );
+    expect(
+      LibraryExportService.locationFor(
+        'webdav|https://user@dav.example.com:8443/dav|/Movies/100% Wolf.mkv',
+      ),
+      'https://user@dav.example.com:8443/dav/Movies/100%25%20Wolf.mkv',
+    );
+  });
+
+  test('DLNA URLs are exported as stored, without re-encoding', () {
+    const url = 'http://192.168.1.20:50002/v/Dune%20Part%20One.mkv?q=x';
+    expect(LibraryExportService.locationFor(url), url);
+  });
+}
@@ -76,4 +76,44 @@ void main() {
     expect(result.tvCount, 0);
     expect(result.missingFolders, 1);
   });
+
+  test('prunes NAS housekeeping directories', () async {
+    await createFile('Movies/Dune.2021.mkv');
+    await createFile(
+      'Movies/@eaDir/Dune.2021.mkv/SYNOVIDEO_VIDEO_SCREENSHOT.mkv',
+    );
+    await createFile('Movies/#recycle/Old.Movie.2001.mkv');
+
+    final result = await scanner.scanFolders([
+      path.join(tempDir.path, 'Movies'),
+    ]);
+
+    expect(result.scannedFiles, 1);
+    expect((await repo.getByType(MediaType.movie)).single.title, 'Dune');
+  });
+
+  test('defers files a download client is still writing', () async {
+    await createFile('Movies/Dune.2021.mkv');
+    await createFile('Movies/Arrival.2016.mkv');
+    await createFile('Movies/Arrival.2016.mkv.aria2');
+
+    final result = await scanner.scanFolders([
+      path.join(tempDir.path, 'Movies'),
+    ]);
+
+    expect(result.scannedFiles, 1);
+    expect((await repo.getByType(MediaType.movie)).single.title, 'Dune');
+  });
+
+  test('honours an overridden exclusion list', () async {
+    await createFile('Movies/Dune.2021.mkv');
+    await createFile('Movies/Backup/Dune.2021.Remux.mkv');
+
+    final custom = LibraryScannerService(repo, null, {'backup'});
+    final result = await custom.scanFolders([
+      path.join(tempDir.path, 'Movies'),
+    ]);
+
+    expect(result.scannedFiles, 1);
+  });
 }
@@ -0,0 +1,78 @@
+import 'package:drift/native.dart';
+import 'package:flutter_test/flutter_test.dart';
+import 'package:open_filmly/data/database/database.dart';
+import 'package:open_filmly/data/models/episode.dart';
+import 'package:open_filmly/data/models/media.dart';
+import 'package:open_filmly/data/repositories/episode_repository.dart';
+import 'package:open_filmly/data/repositories/media_repository.dart';
+import 'package:open_filmly/services/library/library_stats_service.dart';
+
+void main() {
+  late AppDatabase db;
+  late LibraryStatsService stats;
+
+  setUp(() async {
+    db = AppDatabase(NativeDatabase.memory());
+    final media = MediaRepository(db);
+    final episodes = EpisodeRepository(db);
+    stats = LibraryStatsService(media, episodes);
+
+    await media.upsert(
+      const Media(
+        id: 'dune',
+        title: 'Dune',
+        year: '2021',
+        type: MediaType.movie,
+        path: '/Movies/Dune.2021.2160p.BluRay.x265.mkv',
+        detailsJson: '{"tmdbId":438631}',
+      ),
+    );
+    await media.upsert(
+      const Media(
+        id: 'arrival',
+        title: 'Arrival',
+        year: '2016',
+        type: MediaType.movie,
+        path: '/Movies/Arrival.mkv',
+      ),
+    );
+    await media.upsert(
+      const Media(
+        id: 'show',
+        title: '漫长的季节',
+        year: '2023',
+        type: MediaType.tv,
+        path: 'show',
+        detailsJson: '{"tmdbId":221851}',
+      ),
+    );
+    for (final number in [1, 2]) {
+      await episodes.upsert(
+        Episode(
+          id: 'show-$number',
+          showId: 'show',
+          seasonNumber: 1,
+          episodeNumber: number,
+          path: '/TV/漫长的季节/S01E0$number.1080p.WEB-DL.H264.mkv',
+        ),
+      );
+    }
+  });
+
+  tearDown(() => db.close());
+
+  test('counts titles, files by quality, and unmatched items', () async {
+    final result = await stats.collect();
+
+    expect(result.movieCount, 2);
+    expect(result.showCount, 1);
+    expect(result.episodeCount, 2);
+    expect(result.byYear.keys, ['2023', '2021', '2016']);
+    expect(result.byResolution, {'1080p': 2, '4K': 1, '未知': 1});
+    expect(result.byCodec, {'AVC': 2, 'HEVC': 1, '未知': 1});
+    expect(result.unmatched.map((media) => media.title), ['Arrival']);
+    expect(result.toJson()['unmatched'], [
+      {'title': 'Arrival', 'path': '/Movies/Arrival.mkv'},
+    ]);
+  });
+}
@@ -0,0 +1,167 @@
+import 'dart:io';
+
+import 'package:drift/native.dart';
+import 'package:flutter_test/flutter_test.dart';
+import 'package:open_filmly/data/database/database.dart';
+import 'package:open_filmly/data/models/episode.dart';
+import 'package:open_filmly/data/models/media.dart';
+import 'package:open_filmly/data/repositories/episode_repository.dart';
+import 'package:open_filmly/data/repositories/media_repository.dart';
+import 'package:open_filmly/services/library/library_validation_service.dart';
+import 'package:open_filmly/services/smb/smb_path.dart';
+import 'package:open_filmly/services/smb/smb_service.dart';
+import 'package:smb_connect/smb_connect.dart';
+
+import 'test_support/fake_smb_service.dart';
+
+/// Answers lookups for [files] and [shares]; anything else doesn't exist.
+class _NasSmbService extends FakeSmbService {
+  _NasSmbService({required this.files, required this.shares})
+    : super(initialConfig: const SmbConfig(host: 'nas'));
+
+  final Set<String> files;
+  final Set<String> shares;
+
+  @override
+  Future<SmbFile> openFolder(String path) async {
+    final normalized = SmbPath.normalize(path).serverPath;
+    if (files.contains(normalized)) return smbFile(normalized, size: 10);
+    if (shares.contains(normalized)) return smbDir(normalized);
+    return SmbFile(normalized, '', '', 0, 0, 0, 0x20, 0, false);
+  }
+}
+
+void main() {
+  late AppDatabase db;
+  late Directory dir;
+  late MediaRepository media;
+  late EpisodeRepository episodes;
+
+  setUp(() async {
+    db = AppDatabase(NativeDatabase.memory());
+    dir = await Directory.systemTemp.createTemp('validation-test');
+    media = MediaRepository(db);
+    episodes = EpisodeRepository(db);
+
+    final healthy = File('${dir.path}/Dune.mkv')..writeAsBytesSync([1, 2, 3]);
+    final empty = File('${dir.path}/Arrival.mkv')..writeAsBytesSync([]);
+    for (final (id, path) in [
+      ('dune', healthy.path),
+      ('arrival', empty.path),
+      ('gone', '${dir.path}/Gone.mkv'),
+      ('emby', 'emby-item-1'),
+    ]) {
+      await media.upsert(
+        Media(id: id, title: id, year: '', type: MediaType.movie, path: path),
+      );
+    }
+    await media.upsert(
+      const Media(
+        id: 'show',
+        title: 'show',
+        year: '',
+        type: MediaType.tv,
+        path: 'show',
+      ),
+    );
+    await episodes.upsert(
+      Episode(
+        id: 'show-1',
+        showId: 'show',
+        seasonNumber: 1,
+        episodeNumber: 1,
+        path: '${dir.path}/S01E01.mkv',
+      ),
+    );
+  });
+
+  tearDown(() async {
+    await db.close();
+    await dir.delete(recursive: true);
+  });
+
+  test('reports missing and empty files and skips remote ids', () async {
+    final report = await LibraryValidationService(media, episodes).validate();
+
+    expect(report.checked, 4);
+    expect(report.skipped, 1);
+    final codes = {
+      for (final issue in report.issues)
+        issue.episodeId ?? issue.mediaId: issue.code,
+    };
+    expect(codes, {
+      'arrival': LibraryIssueCode.empty,
+      'gone': LibraryIssueCode.missing,
+      'show-1': LibraryIssueCode.missing,
+    });
+  });
+
+  test('removeMissing deletes only missing entries', () async {
+    final service = LibraryValidationService(media, episodes);
+    final removed = await service.removeMissing(
+      (await service.validate()).issues,
+    );
+
+    expect(removed, 2);
+    expect(await media.getById('gone'), isNull);
+    expect(await media.getById('arrival'), isNotNull);
+    expect(await episodes.getByShow('show'), isEmpty);
+  });
+
+  Map<String, LibraryIssueCode> codesOf(LibraryValidationReport report) => {
+    for (final issue in report.issues)
+      issue.episodeId ?? issue.mediaId: issue.code,
+  };
+
+  test('skips files under an unplugged drive instead of missing', () async {
+    final drive = '${dir.path}/Drive';
+    await media.upsert(
+      Media(
+        id: 'unplugged',
+        title: 'unplugged',
+        year: '',
+        type: MediaType.movie,
+        path: '$drive/Movie.mkv',
+      ),
+    );
+
+    final report = await LibraryValidationService(
+      media,
+      episodes,
+      null,
+      [dir.path, drive],
+    ).validate();
+
+    expect(report.unreachableRoots, [drive]);
+    expect(codesOf(report), isNot(contains('unplugged')));
+    expect(codesOf(report)['gone'], LibraryIssueCode.missing);
+  });
+
+  test('checks SMB names verbatim and skips unreachable shares', () async {
+    final smb = _NasSmbService(
+      files: {'/Media/100%41 Pure.mkv'},
+      shares: {'/Media'},
+    );
+    for (final (id, path) in [
+      ('literal', 'smb://nas/Media/100%41 Pure.mkv'),
+      ('deleted', 'smb://nas/Media/Deleted.mkv'),
+      ('offline', 'smb://nas/Offline/Movie.mkv'),
+    ]) {
+      await media.upsert(
+        Media(id: id, title: id, year: '', type: MediaType.movie, path: path),
+      );
+    }
+
+    final report = await LibraryValidationService(
+      media,
+      episodes,
+      smb,
+    ).validate();
+
+    final codes = codesOf(report);
+    expect(codes, isNot(contains('literal')));
+    expect(codes['deleted'], LibraryIssueCode.missing);
+    expect(codes, isNot(contains('offline')));
+    expect(report.unreachableRoots, ['smb://nas/Offline']);
+  });
+}
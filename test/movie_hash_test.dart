@@ -0,0 +1,47 @@
+import 'dart:typed_data';
+
+import 'package:flutter_test/flutter_test.dart';
+import 'package:open_filmly/services/streaming/movie_hash.dart';
+import 'package:open_filmly/services/streaming/range_source.dart';
+
+class _BytesSource implements RangeSource {
+  _BytesSource(this.data);
+
+  final Uint8List data;
+  final reads = <(int, int)>[];
+
+  @override
+  Future<int> length(String id) async => data.length;
+
+  @override
+  Future<Stream<List<int>>> read(String id, int start, int endInclusive) async {
+    reads.add((start, endInclusive));
+    // Deliver in uneven pieces, like a network stream.
+    final slice = data.sublist(start, endInclusive + 1);
+    return Stream.fromIterable([slice.sublist(0, 1000), slice.sublist(1000)]);
+  }
+}
+
+void main() {
+  test('hash of an all-zero file is its size', () async {
+    final source = _BytesSource(Uint8List(128 * 1024));
+
+    expect(await computeMovieHash(source, 'zero.mkv'), '0000000000020000');
+  });
+
+  test('sums only the head and tail windows', () async {
+    final data = Uint8List.fromList(
+      List.generate(200000, (i) => (i * 7 + 3) % 256),
+    );
+    final source = _BytesSource(data);
+
+    expect(await computeMovieHash(source, 'movie.mkv'), '60a0df1f5fa2cd40');
+    expect(source.reads, [(0, 65535), (200000 - 65536, 199999)]);
+  });
+
+  test('rejects files smaller than one window', () async {
+    final source = _BytesSource(Uint8List(1024));
+
+    await expectLater(computeMovieHash(source, 'tiny.srt'), throwsArgumentError);
+  });
+}
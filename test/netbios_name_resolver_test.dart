@@ -0,0 +1,68 @@
+import 'package:flutter_test/flutter_test.dart';
+import 'package:open_filmly/services/smb/netbios_name_resolver.dart';
+
+/// Never gets an answer, counting how often it is asked.
+class _SilentResolver extends NetbiosNameResolver {
+  _SilentResolver();
+
+  var queries = 0;
+
+  @override
+  Future<String?> query(
+    String name, {
+    Duration timeout = const Duration(seconds: 2),
+  }) async {
+    queries++;
+    return null;
+  }
+}
+
+void main() {
+  test('encodes the name as 32 half-bytes with the server suffix', () {
+    final encoded = NetbiosNameResolver.encodeName('mynas');
+
+    expect(encoded.first, 0x20);
+    expect(encoded.last, 0x00);
+    expect(
+      String.fromCharCodes(encoded.sublist(1, 33)),
+      'ENFJEOEBFDCACACACACACACACACACACA',
+    );
+  });
+
+  test('builds a broadcast name query', () {
+    final query = NetbiosNameResolver.buildQuery('MYNAS', 0x1234);
+
+    expect(query.sublist(0, 4), [0x12, 0x34, 0x01, 0x10]);
+    expect(query.length, 12 + 34 + 4);
+  });
+
+  test('reads the address from a positive response', () {
+    final response = [
+      // Header: id, response flags, one answer.
+      0x12, 0x34, 0x85, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+      ...NetbiosNameResolver.encodeName('MYNAS'),
+      // NB, IN, TTL, RDLENGTH.
+      0x00, 0x20, 0x00, 0x01, 0x00, 0x04, 0x93, 0xe0, 0x00, 0x06,
+      // NB_FLAGS, address.
+      0x00, 0x00, 192, 168, 1, 20,
+    ];
+
+    expect(NetbiosNameResolver.parseResponse(response, 0x1234), '192.168.1.20');
+    expect(NetbiosNameResolver.parseResponse(response, 0x9999), isNull);
+  });
+
+  test('only treats single-label names as NetBIOS names', () {
+    expect(NetbiosNameResolver.isNetbiosName('MYNAS'), isTrue);
+    expect(NetbiosNameResolver.isNetbiosName('nas.local'), isFalse);
+    expect(NetbiosNameResolver.isNetbiosName('192.168.1.20'), isFalse);
+    expect(NetbiosNameResolver.isNetbiosName('a-very-long-host-name'), isFalse);
+  });
+
+  test('does not query again for a name that just went unanswered', () async {
+    final resolver = _SilentResolver();
+
+    expect(await resolver.resolve('NOSUCHNAS42'), 'NOSUCHNAS42');
+    expect(await resolver.resolve('nosuchnas42'), 'nosuchnas42');
+    expect(resolver.queries, 1);
+  });
+}
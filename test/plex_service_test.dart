@@ -0,0 +1,226 @@
+import 'dart:convert';
+import 'dart:io';
+
+import 'package:drift/native.dart';
+import 'package:flutter_test/flutter_test.dart';
+import 'package:http/http.dart' as http;
+import 'package:open_filmly/core/image/filmly_image_cache.dart';
+import 'package:open_filmly/data/database/database.dart';
+import 'package:open_filmly/data/models/media.dart';
+import 'package:open_filmly/data/repositories/episode_repository.dart';
+import 'package:open_filmly/data/repositories/media_repository.dart';
+import 'package:open_filmly/services/library/media_library_entry_factory.dart';
+import 'package:open_filmly/services/library/plex_library_import_service.dart';
+import 'package:open_filmly/services/plex/plex_service.dart';
+
+void main() {
+  late HttpServer server;
+  late http.Client client;
+  late PlexService plex;
+  late String base;
+
+  setUp(() async {
+    server = await HttpServer.bind(InternetAddress.loopbackIPv4, 0);
+    client = http.Client();
+    plex = PlexService(client);
+    base = 'http://127.0.0.1:${server.port}';
+
+    server.listen((request) async {
+      if (request.uri.queryParameters['X-Plex-Token'] != 'tok123') {
+        request.response.statusCode = 401;
+        await request.response.close();
+        return;
+      }
+      final path = request.uri.path;
+      Object? body;
+      if (path == '/library/sections') {
+        body = {
+          'MediaContainer': {
+            'Directory': [
+              {'key': '1', 'type': 'movie', 'title': 'Movies'},
+              {'key': '2', 'type': 'show', 'title': 'TV'},
+              {'key': '3', 'type': 'artist', 'title': 'Music'},
+            ],
+          },
+        };
+      } else if (path == '/library/sections/1/all') {
+        body = {
+          'MediaContainer': {
+            'Metadata': [
+              {
+                'ratingKey': '10',
+                'title': 'Dune',
+                'type': 'movie',
+                'year': 2021,
+                'thumb': '/library/metadata/10/thumb/1',
+                'Media': [
+                  {
+                    'Part': [
+                      {'key': '/library/parts/100/1/file.mkv'},
+                    ],
+                  },
+                ],
+              },
+            ],
+          },
+        };
+      } else if (path == '/library/sections/2/all') {
+        body = {
+          'MediaContainer': {
+            'Metadata': [
+              {'ratingKey': '20', 'title': 'Dark', 'type': 'show'},
+            ],
+          },
+        };
+      } else if (path == '/library/metadata/20/allLeaves') {
+        body = {
+          'MediaContainer': {
+            'Metadata': [
+              {
+                'ratingKey': '21',
+                'title': 'Secrets',
+                'type': 'episode',
+                'parentIndex': 1,
+                'index': 1,
+                'Media': [
+                  {
+                    'Part': [
+                      {'key': '/library/parts/210/1/file.mkv'},
+                    ],
+                  },
+                ],
+              },
+            ],
+          },
+        };
+      }
+      if (body == null) {
+        request.response.statusCode = 404;
+      } else {
+        request.response
+          ..statusCode = 200
+          ..write(jsonEncode(body));
+      }
+      await request.response.close();
+    });
+  });
+
+  tearDown(() async {
+    client.close();
+    await server.close(force: true);
+  });
+
+  test('lists movies and shows from video sections only', () async {
+    await plex.connect(PlexConfig(url: base, token: 'tok123'));
+
+    final items = await plex.fetchLibrary();
+
+    expect(items.map((item) => item.title), ['Dune', 'Dark']);
+    expect(items.first.partKey, '/library/parts/100/1/file.mkv');
+    expect(items.first.year, '2021');
+    expect(items.last.partKey, isNull);
+  });
+
+  test('fetches episodes with season/episode numbers', () async {
+    await plex.connect(PlexConfig(url: base, token: 'tok123'));
+
+    final episodes = await plex.fetchEpisodes('20');
+
+    expect(episodes.single.title, 'Secrets');
+    expect(episodes.single.seasonNumber, 1);
+    expect(episodes.single.episodeNumber, 1);
+  });
+
+  test('stream URL embeds the token', () async {
+    await plex.connect(PlexConfig(url: base, token: 'tok123'));
+
+    expect(
+      plex.streamUrl('/library/parts/100/1/file.mkv'),
+      '$base/library/parts/100/1/file.mkv?X-Plex-Token=tok123',
+    );
+  });
+
+  test('poster URLs leave the token for the image loader to add', () async {
+    await plex.connect(PlexConfig(url: base, token: 'tok123'));
+
+    final poster = plex.imageUrl('/library/metadata/10/thumb/1');
+    expect(poster, '$base/library/metadata/10/thumb/1');
+
+    FilmlyImageCache.setOriginQuery(base, {'X-Plex-Token': 'tok123'});
+    addTearDown(() => FilmlyImageCache.setOriginQuery(base, const {}));
+    expect(
+      FilmlyImageCache.networkUrl(poster),
+      '$base/library/metadata/10/thumb/1?X-Plex-Token=tok123',
+    );
+    expect(
+      FilmlyImageCache.networkUrl('http://other.local/poster.jpg'),
+      'http://other.local/poster.jpg',
+    );
+  });
+
+  test('import keeps the token out of stored poster URLs', () async {
+    final db = AppDatabase(NativeDatabase.memory());
+    addTearDown(db.close);
+    final repo = MediaRepository(db);
+    await plex.connect(PlexConfig(url: base, token: 'tok123'));
+
+    await PlexLibraryImportService(
+      plex,
+      repo,
+      EpisodeRepository(db),
+    ).importLibrary();
+
+    final movie = (await repo.getByType(MediaType.movie)).single;
+    expect(movie.posterPath, '$base/library/metadata/10/thumb/1');
+  });
+
+  test('connect rejects a bad token', () async {
+    await expectLater(
+      plex.connect(PlexConfig(url: base, token: 'nope')),
+      throwsStateError,
+    );
+    expect(plex.isConnected, isFalse);
+  });
+
+  test('entry factory round-trips a Plex movie source', () {
+    final media = MediaLibraryEntryFactory.fromPlexMovieOrShow(
+      baseUrl: 'http://plex.local:32400',
+      ratingKey: '10',
+      title: 'Dune',
+      year: '2021',
+      isShow: false,
+      partKey: '/library/parts/100/1/file.mkv',
+    );
+    expect(media.type, MediaType.movie);
+
+    final source = MediaLibraryEntryFactory.plexSourceFor(media);
+    expect(source!.ratingKey, '10');
+    expect(source.partKey, '/library/parts/100/1/file.mkv');
+  });
+
+  test('episode playback keeps the Plex rating key', () {
+    final show = MediaLibraryEntryFactory.fromPlexMovieOrShow(
+      baseUrl: 'http://plex.local:32400',
+      ratingKey: '20',
+      title: 'Dark',
+      year: '2017',
+      isShow: true,
+    );
+    final episode = MediaLibraryEntryFactory.fromPlexEpisode(
+      showId: show.id,
+      ratingKey: '21',
+      partKey: '/library/parts/210/1/file.mkv',
+      seasonNumber: 1,
+      episodeNumber: 1,
+      title: 'Secrets',
+    );
+
+    final playable = MediaLibraryEntryFactory.episodePlayableMedia(
+      episode,
+      show,
+    );
+    final source = MediaLibraryEntryFactory.plexSourceFor(playable);
+    expect(source!.ratingKey, '21');
+    expect(source.partKey, '/library/parts/210/1/file.mkv');
+  });
+}
@@ -0,0 +1,52 @@
+import 'package:flutter_test/flutter_test.dart';
+import 'package:open_filmly/data/models/release_info.dart';
+
+void main() {
+  test('parses a UHD remux release name', () {
+    final info = ReleaseInfo.parse(
+      '/m/Dune.2021.2160p.UHD.BluRay.REMUX.DV.HDR10.HEVC.TrueHD.7.1-FGT.mkv',
+    );
+
+    expect(info.resolution, 2160);
+    expect(info.source, 'Remux');
+    expect(info.codec, 'HEVC');
+    expect(info.hdr, ['DV', 'HDR10']);
+    expect(info.releaseGroup, 'FGT');
+    expect(info.badges, ['4K', 'Remux', 'HEVC', 'DV', 'HDR10']);
+  });
+
+  test('reads dotted codecs and ignores source suffixes as groups', () {
+    final info = ReleaseInfo.parse(
+      r'D:\Films\Arrival.2016.1080p.H.264.WEB-DL.mkv',
+    );
+
+    expect(info.resolution, 1080);
+    expect(info.source, 'WEB-DL');
+    expect(info.codec, 'AVC');
+    expect(info.hdr, isEmpty);
+    expect(info.releaseGroup, isNull);
+  });
+
+  test('does not mistake hyphenated titles for release groups', () {
+    final info = ReleaseInfo.parse('/m/Spider-Man.2002.mkv');
+
+    expect(info.releaseGroup, isNull);
+    expect(info.isEmpty, isTrue);
+    expect(ReleaseInfo.parse('/m/Spider-Man.mkv').releaseGroup, isNull);
+    expect(
+      ReleaseInfo.parse(
+        '/m/Spider-Man.2002.1080p.BluRay-SPARKS.mkv',
+      ).releaseGroup,
+      'SPARKS',
+    );
+  });
+
+  test('ranks resolution ahead of source', () {
+    final uhdWeb = ReleaseInfo.parse('Film.2160p.WEB-DL.mkv');
+    final hdRemux = ReleaseInfo.parse('Film.1080p.BluRay.REMUX.mkv');
+    final hdWeb = ReleaseInfo.parse('Film.1080p.WEBRip.mkv');
+
+    expect(uhdWeb.score, greaterThan(hdRemux.score));
+    expect(hdRemux.score, greaterThan(hdWeb.score));
+  });
+}
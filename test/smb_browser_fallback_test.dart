@@ -21,7 +21,7 @@ void main() {
     await db.close();
   });
 
-  Future<void> connect(WidgetTester tester, FakeSmbService smb) async {
+  Future<void> connect(WidgetTester tester, SmbService smb) async {
     tester.view.physicalSize = const Size(1200, 1000);
     tester.view.devicePixelRatio = 1.0;
     addTearDown(tester.view.resetPhysicalSize);
@@ -88,4 +88,73 @@ void main() {
     await tester.pumpAndSettle();
     expect(find.text('x.mkv'), findsOneWidget);
   });
+
+  testWidgets('shows the connect time after a real connect', (tester) async {
+    final smb = SlowLoginSmbService(shares: [smbShare('Media')]);
+
+    await connect(tester, smb);
+
+    expect(smb.lastConnectTimings, isNotNull);
+    final ms = smb.lastConnectTimings!.total.inMilliseconds;
+    expect(find.textContaining('已连接（$ms ms）'), findsOneWidget);
+    expect(find.text('Media'), findsOneWidget);
+  });
+
+  testWidgets('warns when the opened share is read-only', (tester) async {
+    final smb = FakeSmbService(
+      initialConfig: const SmbConfig(host: 'nas'),
+      connected: false,
+      failShares: true,
+      directories: {
+        '/Media': [smbFile('/Media/Dune.2021.mkv', size: 10)],
+      },
+      readOnlyFolders: {'/Media'},
+    );
+
+    await connect(tester, smb);
+    await tester.tap(find.text('Media'));
+    await tester.pumpAndSettle();
+    await tester.tap(find.byKey(const Key('smb_check_access_button')));
+    await tester.pumpAndSettle();
+
+    expect(find.text('只读共享：可以浏览和播放，无法写入'), findsOneWidget);
+  });
+
+  testWidgets('reuses folder listings until refreshed or hidden toggles', (
+    tester,
+  ) async {
+    final smb = FakeSmbService(
+      initialConfig: const SmbConfig(host: 'nas'),
+      connected: false,
+      failShares: true,
+      directories: {
+        '/Media': [smbDir('/Media/Movies')],
+        '/Media/Movies': [smbFile('/Media/Movies/Dune.2021.mkv', size: 10)],
+      },
+    );
+    int moviesListings() =>
+        smb.listedPaths.where((path) => path == '/Media/Movies').length;
+
+    await connect(tester, smb);
+    await tester.tap(find.text('Media'));
+    await tester.pumpAndSettle();
+    await tester.tap(find.text('Movies'));
+    await tester.pumpAndSettle();
+    expect(moviesListings(), 1);
+
+    await tester.tap(find.text('上级目录'));
+    await tester.pumpAndSettle();
+    await tester.tap(find.text('Movies'));
+    await tester.pumpAndSettle();
+    expect(moviesListings(), 1);
+
+    await tester.tap(find.byKey(const Key('smb_refresh_button')));
+    await tester.pumpAndSettle();
+    expect(moviesListings(), 2);
+
+    await tester.tap(find.byKey(const Key('smb_toggle_hidden_button')));
+    await tester.pumpAndSettle();
+    expect(moviesListings(), 3);
+    expect(find.text('Dune.2021.mkv'), findsOneWidget);
+  });
 }
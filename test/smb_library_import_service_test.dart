@@ -1,3 +1,5 @@
+import 'dart:convert';
+
 import 'package:drift/native.dart';
 import 'package:flutter_test/flutter_test.dart';
 import 'package:open_filmly/data/database/database.dart';
@@ -5,12 +7,49 @@ import 'package:open_filmly/data/models/episode.dart';
 import 'package:open_filmly/data/models/media.dart';
 import 'package:open_filmly/data/repositories/episode_repository.dart';
 import 'package:open_filmly/data/repositories/media_repository.dart';
+import 'package:open_filmly/data/repositories/scan_checkpoint_repository.dart';
 import 'package:open_filmly/services/library/media_library_entry_factory.dart';
 import 'package:open_filmly/services/library/smb_library_import_service.dart';
 import 'package:open_filmly/services/smb/smb_service.dart';
+import 'package:smb_connect/smb_connect.dart';
 
 import 'test_support/fake_smb_service.dart';
 
+/// Fails the first listing of [failOnce], like a NAS dropping mid-scan.
+class _DroppingSmbService extends FakeSmbService {
+  _DroppingSmbService({
+    required super.initialConfig,
+    required super.directories,
+    required this.failOnce,
+  });
+
+  String? failOnce;
+  final listed = <String>[];
+
+  @override
+  Future<List<SmbFile>> listChildren(SmbFile folder) {
+    listed.add(folder.path);
+    if (folder.path == failOnce) {
+      failOnce = null;
+      throw StateError('connection reset');
+    }
+    return super.listChildren(folder);
+  }
+}
+
+/// Counts checkpoint writes, to check they are batched.
+class _CountingCheckpoints extends ScanCheckpointRepository {
+  _CountingCheckpoints(super.db);
+
+  var saves = 0;
+
+  @override
+  Future<void> save(String scanId, ScanCheckpoint checkpoint) {
+    saves++;
+    return super.save(scanId, checkpoint);
+  }
+}
+
 void main() {
   late AppDatabase db;
   late MediaRepository repo;
@@ -72,6 +111,25 @@ void main() {
     expect(shows.single.year, '2008');
   });
 
+  test('imports a folder listed across several pages', () async {
+    smb = FakeSmbService(
+      initialConfig: const SmbConfig(host: 'nas', username: 'guest'),
+      pageSize: 3,
+      directories: {
+        '/Media': [
+          for (var i = 1; i <= 7; i++) smbFile('/Media/Movie.$i.2020.mkv'),
+          smbDir('/Media/More'),
+        ],
+        '/Media/More': [smbFile('/Media/More/Arrival.2016.mkv')],
+      },
+    );
+    importer = SmbLibraryImportService(smb, repo, episodeRepo);
+
+    final result = await importer.importFolder(smbDir('/Media'));
+
+    expect(result.scannedFiles, 8);
+  });
+
   test('requires an active SMB connection', () async {
     await smb.disconnect();
     await expectLater(
@@ -125,4 +183,84 @@ void main() {
     expect(seasons.map((season) => season.number), [1, 2]);
     expect(await repo.getById('smb://nas/Media/Dark 第一季'), isNull);
   });
+
+  test('resumes an interrupted scan from its checkpoint', () async {
+    final checkpoints = ScanCheckpointRepository(db);
+    final dropping = _DroppingSmbService(
+      initialConfig: const SmbConfig(host: 'nas', username: 'guest'),
+      directories: {
+        '/Media': [smbDir('/Media/Movies'), smbDir('/Media/TV')],
+        '/Media/Movies': [smbFile('/Media/Movies/Dune.2021.mkv')],
+        '/Media/TV': [smbFile('/Media/TV/Severance.S01E01.mkv')],
+      },
+      failOnce: '/Media/TV',
+    );
+    final resumable = SmbLibraryImportService(
+      dropping,
+      repo,
+      episodeRepo,
+      MediaLibraryEntryFactory.defaultExcludedDirectoryNames,
+      checkpoints,
+    );
+    const scanId = 'smb|nas|/Media';
+
+    await expectLater(
+      resumable.importFolder(smbDir('/Media')),
+      throwsStateError,
+    );
+    final checkpoint = await checkpoints.load(scanId);
+    expect(checkpoint.completed, {'/Media/Movies'});
+    final movieId = (await repo.getByType(MediaType.movie)).single.id;
+    expect(checkpoint.mediaIds, [movieId]);
+
+    dropping.listed.clear();
+    final result = await resumable.importFolder(smbDir('/Media'));
+
+    expect(result.resumed, isTrue);
+    expect(dropping.listed, ['/Media', '/Media/TV']);
+    expect(result.movieCount, 0);
+    expect(result.tvCount, 1);
+    expect(await repo.getByType(MediaType.movie), hasLength(1));
+    // The movie imported before the drop is still handed back for enrichment.
+    expect(result.mediaIds, contains(movieId));
+    expect((await checkpoints.load(scanId)).isEmpty, isTrue);
+  });
+
+  test('batches checkpoint saves during a scan', () async {
+    final checkpoints = _CountingCheckpoints(db);
+    final result = await SmbLibraryImportService(
+      smb,
+      repo,
+      episodeRepo,
+      MediaLibraryEntryFactory.defaultExcludedDirectoryNames,
+      checkpoints,
+    ).importFolder(smbDir('/Media'));
+
+    // Five folders finish, fewer than one batch, and the scan completes.
+    expect(result.movieCount, 1);
+    expect(checkpoints.saves, 0);
+    expect((await checkpoints.load('smb|nas|/Media')).isEmpty, isTrue);
+  });
+
+  test('drops checkpoints older than the maximum age', () async {
+    final checkpoints = ScanCheckpointRepository(db);
+    const scanId = 'smb|nas|/Media';
+    final stale = DateTime.now().subtract(
+      ScanCheckpointRepository.maxAge + const Duration(days: 1),
+    );
+    await db
+        .into(db.configEntries)
+        .insert(
+          ConfigEntriesCompanion.insert(
+            key: 'scan_checkpoint:$scanId',
+            value: jsonEncode({
+              'completed': ['/Media/Movies'],
+              'updatedAt': stale.toIso8601String(),
+            }),
+          ),
+        );
+
+    expect((await checkpoints.load(scanId)).isEmpty, isTrue);
+    expect(await db.select(db.configEntries).get(), isEmpty);
+  });
 }
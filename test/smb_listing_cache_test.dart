@@ -0,0 +1,37 @@
+import 'package:flutter_test/flutter_test.dart';
+import 'package:open_filmly/services/smb/smb_listing_cache.dart';
+
+import 'test_support/fake_smb_service.dart';
+
+void main() {
+  test('expires listings after the TTL', () {
+    var now = DateTime(2026, 1, 1, 12);
+    final cache = SmbListingCache(
+      ttl: const Duration(seconds: 30),
+      now: () => now,
+    );
+    final listing = [smbFile('/Media/Dune.2021.mkv')];
+
+    cache.put('/Media', listing);
+    now = now.add(const Duration(seconds: 29));
+    expect(cache.get('/Media'), same(listing));
+
+    now = now.add(const Duration(seconds: 1));
+    expect(cache.get('/Media'), isNull);
+    expect(cache.length, 0);
+  });
+
+  test('drops the oldest listing past the entry cap', () {
+    final cache = SmbListingCache(maxEntries: 2);
+
+    cache.put('/a', const []);
+    cache.put('/b', const []);
+    cache.put('/a', const []); // refreshed, so /b is now the oldest
+    cache.put('/c', const []);
+
+    expect(cache.length, 2);
+    expect(cache.get('/a'), isNotNull);
+    expect(cache.get('/b'), isNull);
+    expect(cache.get('/c'), isNotNull);
+  });
+}
@@ -13,6 +13,7 @@ class _MemorySource implements RangeSource {
 
   final Uint8List data;
   final readCalls = <_ReadCall>[];
+  final readIds = <String>[];
 
   @override
   Future<int> length(String id) async => data.length;
@@ -21,6 +22,7 @@ class _MemorySource implements RangeSource {
   Future<Stream<List<int>>> read(String id, int start, int endInclusive) async {
     // endInclusive is inclusive, matching the SmbService contract.
     readCalls.add(_ReadCall(start, endInclusive));
+    readIds.add(id);
     return Stream.value(data.sublist(start, endInclusive + 1));
   }
 }
@@ -164,6 +166,57 @@ void main() {
     await res.drain<void>();
   });
 
+  test('large ranges are read ahead in ordered chunks', () async {
+    await proxy.stop();
+    proxy = SmbProxyServer(
+      source,
+      readAheadChunkBytes: 300,
+      readAheadChunks: 3,
+    );
+    await proxy.start();
+    url = proxy.urlFor('any/movie.mkv');
+
+    final res = await request(url, range: 'bytes=100-999');
+    expect(res.statusCode, 206);
+    expect(res.headers.value(HttpHeaders.contentLengthHeader), '900');
+    expect(await _collect(res), equals(data.sublist(100)));
+    expect(
+      source.readCalls.map((call) => '${call.start}-${call.endInclusive}'),
+      ['100-399', '400-699', '700-999'],
+    );
+  });
+
+  test('CJK, emoji, and odd names round-trip through the URL', () async {
+    const sourceId = 'smb://nas/影视/🎬 Collection/Dune (2021) ./沙丘 .mkv';
+    final streamUrl = proxy.urlFor(sourceId, displayName: '沙丘 🎬 .mkv');
+
+    final segments = Uri.parse(streamUrl).pathSegments;
+    expect(segments.last, '沙丘 🎬 .mkv');
+
+    final res = await request(streamUrl, range: 'bytes=0-9');
+    expect(res.statusCode, 206);
+    expect(await _collect(res), equals(data.sublist(0, 10)));
+    expect(source.readIds.single, sourceId);
+  });
+
+  test('very long display names stay a single path segment', () async {
+    final longName = '${'长' * 300}/part.mkv';
+    final streamUrl = proxy.urlFor('any/long.mkv', displayName: longName);
+
+    expect(Uri.parse(streamUrl).pathSegments.last, longName);
+    final res = await request(streamUrl, method: 'HEAD');
+    expect(res.statusCode, 200);
+    await res.drain<void>();
+  });
+
+  test('dot-only display names are left off the URL', () async {
+    final streamUrl = proxy.urlFor('any/movie.mkv', displayName: '..');
+
+    final res = await request(streamUrl, method: 'HEAD');
+    expect(res.statusCode, 200);
+    await res.drain<void>();
+  });
+
   test('unknown token → 404', () async {
     final res = await request('http://127.0.0.1:${proxy.port}/stream/999999');
     expect(res.statusCode, 404);
@@ -0,0 +1,495 @@
+import 'dart:io';
+
+import 'package:flutter_test/flutter_test.dart';
+import 'package:open_filmly/services/smb/smb_service.dart';
+import 'package:smb_connect/smb_connect.dart';
+
+import 'test_support/fake_smb_service.dart';
+
+/// Accepts only [password]; every other login fails like a bad NTLM auth.
+class _PickySmbService extends FakeSmbService {
+  _PickySmbService(
+    this.password, {
+    this.lockedOut = const {},
+    this.unreachable = false,
+  }) : super(initialConfig: const SmbConfig(host: 'nas'), connected: false);
+
+  final String password;
+  final Set<String> lockedOut;
+  final bool unreachable;
+  final attempts = <String>[];
+
+  @override
+  Future<void> connect(SmbConfig config) async {
+    attempts.add(config.username);
+    if (unreachable) {
+      throw const SocketException('Connection timed out');
+    }
+    if (lockedOut.contains(config.username)) {
+      throw StateError('STATUS_ACCOUNT_LOCKED_OUT');
+    }
+    if (config.password != password) {
+      throw StateError('STATUS_LOGON_FAILURE');
+    }
+    await super.connect(config);
+  }
+}
+
+/// Fails every call on the first session, like a NAS that rebooted, and
+/// takes a moment to log back in.
+class _DroppingSmbService extends FakeSmbService {
+  _DroppingSmbService() : super(initialConfig: const SmbConfig(host: 'nas'));
+
+  var sessionDropped = true;
+  var connects = 0;
+
+  @override
+  Future<void> connect(SmbConfig config) async {
+    connects++;
+    await Future<void>.delayed(const Duration(milliseconds: 10));
+    sessionDropped = false;
+    await super.connect(config);
+  }
+
+  Future<String> list(String folder) => resilient(() async {
+    if (sessionDropped) throw const SocketException('Connection reset');
+    return folder;
+  });
+}
+
+/// A session whose listings fail like a dropped socket once [dropped].
+class _PagedConnect extends Fake implements SmbConnect {
+  _PagedConnect(this.pages, {this.dropped = false});
+
+  final List<List<SmbFile>> pages;
+  final bool dropped;
+
+  @override
+  Stream<List<SmbFile>> listFilesPaged(
+    SmbFile folder, [
+    String wildcard = '*',
+  ]) => dropped
+      ? Stream.error(const SocketException('Connection reset by peer'))
+      : Stream.fromIterable(pages);
+
+  @override
+  Future close() async {}
+}
+
+/// Lets probe markers be created but never deleted.
+class _UndeletableConnect extends Fake implements SmbConnect {
+  var deletes = 0;
+
+  @override
+  Future<SmbFile> createFile(String path) async => smbFile(path, size: 0);
+
+  @override
+  Future<SmbFile> delete(SmbFile file) async {
+    deletes++;
+    throw StateError('STATUS_CANNOT_DELETE');
+  }
+
+  @override
+  Future close() async {}
+}
+
+/// Hands out [sessions] in order, one per login.
+class _SessionsSmbService extends SlowLoginSmbService {
+  _SessionsSmbService(this.sessions);
+
+  final List<SmbConnect> sessions;
+  var logins = 0;
+
+  @override
+  Future<SmbConnect> openSession(String target, SmbConfig config) async =>
+      sessions[logins++];
+}
+
+/// Refuses every login to `nas`; other hosts get an idle session.
+class _RejectingSmbService extends SlowLoginSmbService {
+  @override
+  Future<SmbConnect> openSession(String target, SmbConfig config) async {
+    if (config.host == 'nas') {
+      throw StateError('STATUS_LOGON_FAILURE');
+    }
+    return _PagedConnect(const []);
+  }
+}
+
+void main() {
+  group('hidden/system entries', () {
+    late FakeSmbService smb;
+
+    setUp(() {
+      smb = FakeSmbService(
+        initialConfig: const SmbConfig(host: 'nas'),
+        directories: {
+          '/Media': [
+            smbFile('/Media/Dune.2021.mkv'),
+            smbFile('/Media/.DS_Store'),
+            smbFile('/Media/Thumbs.db', attributes: 0x20 | 0x02),
+            smbFile('/Media/desktop.ini', attributes: 0x20 | 0x04),
+            smbFile('/Media/Archived.mkv', attributes: 0x20 | 0x1000),
+          ],
+        },
+      );
+    });
+
+    test('are filtered out of listings by default', () async {
+      final names = (await smb.listChildrenByPath('/Media'))
+          .map((entry) => entry.name)
+          .toList();
+
+      expect(names, ['Dune.2021.mkv', 'Archived.mkv']);
+    });
+
+    test('are kept when showHidden is on', () async {
+      smb.showHidden = true;
+
+      final entries = await smb.listChildrenByPath('/Media');
+
+      expect(entries, hasLength(5));
+    });
+
+    test('offline attribute is reported separately', () {
+      expect(
+        SmbService.isOfflineEntry(
+          smbFile('/Media/Archived.mkv', attributes: 0x20 | 0x1000),
+        ),
+        isTrue,
+      );
+      expect(SmbService.isOfflineEntry(smbFile('/Media/Dune.mkv')), isFalse);
+    });
+  });
+
+  group('share listing', () {
+    late FakeSmbService smb;
+
+    setUp(() {
+      smb = FakeSmbService(
+        initialConfig: const SmbConfig(host: 'nas'),
+        directories: {
+          '__shares__': [
+            smbShare('Media'),
+            smbShare(r'IPC$'),
+            smbShare(r'print$'),
+            smbShare(r'ADMIN$'),
+          ],
+        },
+      );
+    });
+
+    test('drops IPC, printer, and admin shares by default', () async {
+      final names = (await smb.listShares()).map((share) => share.name);
+
+      expect(names, ['Media']);
+    });
+
+    test('keeps admin shares when showHidden is on', () async {
+      smb.showHidden = true;
+
+      final names = (await smb.listShares()).map((share) => share.name);
+
+      expect(names, ['Media', r'ADMIN$']);
+    });
+  });
+
+  group('resolveCasing', () {
+    late FakeSmbService smb;
+
+    setUp(() {
+      smb = FakeSmbService(
+        initialConfig: const SmbConfig(host: 'nas'),
+        directories: {
+          '__shares__': [smbShare('Media')],
+          '/Media': [smbDir('/Media/Movies'), smbDir('/Media/movies-old')],
+          '/Media/Movies': [smbFile('/Media/Movies/Dune.2021.mkv')],
+        },
+      );
+    });
+
+    test('finds the server casing component by component', () async {
+      expect(
+        await smb.resolveCasing('/media/MOVIES/dune.2021.MKV'),
+        '/Media/Movies/Dune.2021.mkv',
+      );
+    });
+
+    test('keeps a path that already exists as-is', () async {
+      expect(await smb.resolveCasing('/Media/Movies/'), '/Media/Movies');
+    });
+
+    test('returns null when a component is missing', () async {
+      expect(await smb.resolveCasing('/Media/Movies/Arrival.mkv'), isNull);
+    });
+  });
+
+  group('connectWithAny', () {
+    test('returns the first credential set that authenticates', () async {
+      final smb = _PickySmbService('secret');
+
+      final config = await smb.connectWithAny(const [
+        SmbConfig(host: 'nas', username: 'alice', password: 'wrong'),
+        SmbConfig(host: 'nas', username: 'bob', password: 'secret'),
+        SmbConfig(host: 'nas', username: 'carol', password: 'secret'),
+      ]);
+
+      expect(config.username, 'bob');
+      expect(smb.attempts, ['alice', 'bob']);
+      expect(smb.isConnected, isTrue);
+    });
+
+    test('rethrows the last failure when nothing works', () async {
+      final smb = _PickySmbService('secret');
+
+      await expectLater(
+        smb.connectWithAny(const [
+          SmbConfig(host: 'nas', username: 'alice', password: 'a'),
+          SmbConfig(host: 'nas', username: 'bob', password: 'b'),
+        ]),
+        throwsStateError,
+      );
+      expect(smb.isConnected, isFalse);
+    });
+
+    test('stops retrying once the server reports a lockout', () async {
+      final smb = _PickySmbService('secret', lockedOut: {'alice'});
+
+      await expectLater(
+        smb.connectWithAny(const [
+          SmbConfig(host: 'nas', username: 'alice', password: 'a'),
+          SmbConfig(host: 'nas', username: 'alice', password: 'secret'),
+        ]),
+        throwsStateError,
+      );
+      expect(smb.attempts, ['alice']);
+    });
+
+    test('does not retry other credentials on an unreachable host', () async {
+      final smb = _PickySmbService('secret', unreachable: true);
+
+      await expectLater(
+        smb.connectWithAny(const [
+          SmbConfig(host: 'nas', username: 'alice', password: 'a'),
+          SmbConfig(host: 'nas', username: 'bob', password: 'secret'),
+        ]),
+        throwsA(isA<SocketException>()),
+      );
+      expect(smb.attempts, ['alice']);
+    });
+  });
+
+  group('loginProblem', () {
+    test('classifies refused logins by NT status', () {
+      expect(
+        SmbService.loginProblem(
+          StateError(
+            'The referenced account is currently locked out and may not be '
+            'logged on to.',
+          ),
+        ),
+        SmbLoginProblem.lockedOut,
+      );
+      expect(
+        SmbService.loginProblem(StateError('STATUS_PASSWORD_EXPIRED')),
+        SmbLoginProblem.passwordExpired,
+      );
+      expect(
+        SmbService.loginProblem(
+          StateError('Logon failure: unknown user name or bad password.'),
+        ),
+        SmbLoginProblem.badCredentials,
+      );
+      expect(
+        SmbService.loginProblem(StateError('STATUS_ACCESS_DENIED')),
+        isNull,
+      );
+    });
+  });
+
+  group('checkAccess', () {
+    FakeSmbService share({Set<String> readOnly = const {}}) => FakeSmbService(
+      initialConfig: const SmbConfig(host: 'nas'),
+      directories: {
+        '/Media': [smbFile('/Media/Dune.2021.mkv')],
+      },
+      readOnlyFolders: readOnly,
+    );
+
+    test('reports a writable folder', () async {
+      final report = await share().checkAccess('/Media');
+
+      expect(report.exists, isTrue);
+      expect(report.canList, isTrue);
+      expect(report.canRead, isTrue);
+      expect(report.canWrite, isTrue);
+      expect(report.isReadOnly, isFalse);
+    });
+
+    test('flags a share that refuses writes as read-only', () async {
+      final report = await share(readOnly: {'/Media'}).checkAccess('/Media');
+
+      expect(report.canList, isTrue);
+      expect(report.canWrite, isFalse);
+      expect(report.isReadOnly, isTrue);
+    });
+  });
+
+  test('probeWrite retries the delete and reports a leftover marker', () async {
+    final connection = _UndeletableConnect();
+    final smb = _SessionsSmbService([connection]);
+    await smb.connect(const SmbConfig(host: 'nas'));
+
+    final leftover = await smb.probeWrite('/Media');
+
+    expect(leftover, startsWith('/Media/.open-filmly-access-'));
+    expect(connection.deletes, SmbService.probeDeleteAttempts);
+  });
+
+  test('connect records how long each phase took', () async {
+    final smb = SlowLoginSmbService(
+      resolveDelay: const Duration(milliseconds: 30),
+      sessionDelay: const Duration(milliseconds: 60),
+    );
+    expect(smb.lastConnectTimings, isNull);
+
+    await smb.connect(const SmbConfig(host: 'nas'));
+
+    final timings = smb.lastConnectTimings!;
+    expect(
+      timings.resolve,
+      greaterThanOrEqualTo(const Duration(milliseconds: 30)),
+    );
+    expect(
+      timings.session,
+      greaterThanOrEqualTo(const Duration(milliseconds: 60)),
+    );
+    expect(timings.total, timings.resolve + timings.session);
+    expect(smb.isConnected, isTrue);
+  });
+
+  test('connect timings report each phase and the total', () {
+    const timings = SmbConnectTimings(
+      resolve: Duration(milliseconds: 40),
+      session: Duration(milliseconds: 310),
+    );
+
+    expect(timings.toJson(), {
+      'resolveMs': 40,
+      'sessionMs': 310,
+      'totalMs': 350,
+    });
+  });
+
+  test('isSharingViolation recognizes files held open elsewhere', () {
+    expect(
+      SmbService.isSharingViolation(
+        StateError(
+          'The process cannot access the file because it is being used by '
+          'another process.',
+        ),
+      ),
+      isTrue,
+    );
+    expect(
+      SmbService.isSharingViolation(StateError('STATUS_SHARING_VIOLATION')),
+      isTrue,
+    );
+    expect(
+      SmbService.isSharingViolation(StateError('STATUS_ACCESS_DENIED')),
+      isFalse,
+    );
+  });
+
+  group('isSessionLost', () {
+    test('recognizes expired sessions and dropped sockets', () {
+      expect(
+        SmbService.isSessionLost(
+          StateError('STATUS_NETWORK_SESSION_EXPIRED (0xC000035C)'),
+        ),
+        isTrue,
+      );
+      expect(
+        SmbService.isSessionLost(StateError('Error code: -1073740964')),
+        isTrue,
+      );
+      expect(
+        SmbService.isSessionLost(const SocketException('Connection reset')),
+        isTrue,
+      );
+    });
+
+    test('leaves ordinary failures alone', () {
+      expect(
+        SmbService.isSessionLost(StateError('STATUS_ACCESS_DENIED')),
+        isFalse,
+      );
+      expect(
+        SmbService.isSessionLost(StateError('STATUS_OBJECT_NAME_NOT_FOUND')),
+        isFalse,
+      );
+    });
+  });
+
+  group('session recovery', () {
+    test('concurrent failures share one reconnect and then retry', () async {
+      final smb = _DroppingSmbService();
+
+      final results = await Future.wait([
+        smb.list('/Media'),
+        smb.list('/Movies'),
+        smb.list('/TV'),
+      ]);
+
+      expect(results, ['/Media', '/Movies', '/TV']);
+      expect(smb.connects, 1);
+      expect(smb.reconnectCount, 1);
+    });
+
+    test('does not reconnect after an explicit disconnect', () async {
+      final smb = _DroppingSmbService();
+      await smb.disconnect();
+
+      await expectLater(smb.list('/Media'), throwsA(isA<SocketException>()));
+      expect(smb.connects, 0);
+    });
+  });
+
+  test('paged listing reconnects after the session dropped', () async {
+    final pages = [
+      [smbFile('/Media/a.mkv')],
+      [smbFile('/Media/b.mkv')],
+    ];
+    final smb = _SessionsSmbService([
+      _PagedConnect(pages, dropped: true),
+      _PagedConnect(pages),
+    ]);
+    await smb.connect(const SmbConfig(host: 'nas'));
+
+    final listed = await smb.listChildrenPaged(smbDir('/Media')).toList();
+
+    expect(listed.map((page) => page.single.name), ['a.mkv', 'b.mkv']);
+    expect(smb.logins, 2);
+    expect(smb.reconnectCount, 1);
+  });
+
+  test('a throttled login keeps the current session', () async {
+    final smb = _RejectingSmbService();
+    for (var i = 0; i < SmbService.maxLoginFailures; i++) {
+      await expectLater(
+        smb.connect(const SmbConfig(host: 'nas')),
+        throwsA(isA<StateError>()),
+      );
+    }
+    await smb.connect(const SmbConfig(host: 'backup'));
+
+    await expectLater(
+      smb.connect(const SmbConfig(host: 'nas')),
+      throwsA(
+        isA<StateError>().having((e) => e.message, 'message', contains('过多')),
+      ),
+    );
+    expect(smb.isConnected, isTrue);
+    expect(smb.config?.host, 'backup');
+  });
+}
@@ -1,5 +1,8 @@
 import 'dart:typed_data';
 
+import 'package:flutter_test/flutter_test.dart';
+import 'package:open_filmly/services/smb/netbios_name_resolver.dart';
+import 'package:open_filmly/services/smb/smb_path.dart';
 import 'package:open_filmly/services/smb/smb_service.dart';
 import 'package:smb_connect/smb_connect.dart';
 
@@ -10,6 +13,8 @@ class FakeSmbService extends SmbService {
     Map<String, Uint8List> fileData = const {},
     bool connected = true,
     this.failShares = false,
+    this.pageSize = 2,
+    this.readOnlyFolders = const {},
   }) : _configOverride = connected ? initialConfig : null,
        _connected = connected,
        directories = Map.unmodifiable(directories),
@@ -22,6 +27,15 @@ class FakeSmbService extends SmbService {
   /// enumeration (the real-world "cannot find the file specified" case).
   final bool failShares;
 
+  /// Entries per [listChildrenPaged] page, like a real directory query.
+  final int pageSize;
+
+  /// Folders where [probeWrite] is refused, like a read-only share.
+  final Set<String> readOnlyFolders;
+
+  /// Folder paths passed to [listChildrenByPath], in call order.
+  final List<String> listedPaths = [];
+
   SmbConfig? _configOverride;
   bool _connected;
 
@@ -48,12 +62,12 @@ class FakeSmbService extends SmbService {
     if (failShares) {
       throw 'The system cannot find the file specified.';
     }
-    return directories[_sharesKey] ?? const [];
+    return visibleShares(directories[_sharesKey] ?? const []);
   }
 
   @override
   Future<SmbFile> openFolder(String path) async {
-    final normalized = path.startsWith('/') ? path : '/$path';
+    final normalized = SmbPath.normalize(path).serverPath;
     if (!directories.containsKey(normalized)) {
       throw StateError('Unknown SMB folder: $normalized');
     }
@@ -62,15 +76,32 @@ class FakeSmbService extends SmbService {
 
   @override
   Future<List<SmbFile>> listChildren(SmbFile folder) async {
-    return directories[folder.path] ?? const [];
+    return visibleEntries(directories[folder.path] ?? const []);
+  }
+
+  @override
+  Stream<List<SmbFile>> listChildrenPaged(SmbFile folder) async* {
+    final entries = await listChildren(folder);
+    for (var start = 0; start < entries.length; start += pageSize) {
+      yield entries.skip(start).take(pageSize).toList();
+    }
   }
 
   @override
   Future<List<SmbFile>> listChildrenByPath(String path) async {
+    listedPaths.add(path);
     final folder = await openFolder(path);
     return listChildren(folder);
   }
 
+  @override
+  Future<String?> probeWrite(String folderPath) async {
+    if (readOnlyFolders.contains(SmbPath.normalize(folderPath).serverPath)) {
+      throw StateError('STATUS_ACCESS_DENIED');
+    }
+    return null;
+  }
+
   @override
   Future<int> length(String path) async {
     final data = fileData[path];
@@ -96,6 +127,50 @@ class FakeSmbService extends SmbService {
   static const _sharesKey = '__shares__';
 }
 
+/// Runs the real [SmbService.connect], with the name lookup and the login
+/// each taking a set time, so connect timings can be checked end to end.
+class SlowLoginSmbService extends SmbService {
+  SlowLoginSmbService({
+    Duration resolveDelay = Duration.zero,
+    this.sessionDelay = Duration.zero,
+    this.shares = const [],
+  }) : super(nameResolver: _DelayedResolver(resolveDelay));
+
+  final Duration sessionDelay;
+
+  /// Returned by [listShares] once connected.
+  final List<SmbFile> shares;
+
+  @override
+  Future<SmbConnect> openSession(String target, SmbConfig config) async {
+    await Future<void>.delayed(sessionDelay);
+    return _IdleSmbConnect();
+  }
+
+  @override
+  Future<List<SmbFile>> listShares() async => shares;
+}
+
+class _DelayedResolver extends NetbiosNameResolver {
+  const _DelayedResolver(this.delay);
+
+  final Duration delay;
+
+  @override
+  Future<String> resolve(
+    String host, {
+    Duration timeout = const Duration(seconds: 2),
+  }) async {
+    await Future<void>.delayed(delay);
+    return host;
+  }
+}
+
+class _IdleSmbConnect extends Fake implements SmbConnect {
+  @override
+  Future close() async {}
+}
+
 SmbFile smbShare(String name) {
   final sharePath = '/$name';
   return SmbFile(sharePath, _uncPath(sharePath), name, 0, 0, 0, 0x10, 0, true);
@@ -115,7 +190,7 @@ SmbFile smbDir(String smbPath) {
   );
 }
 
-SmbFile smbFile(String smbPath, {int size = 1}) {
+SmbFile smbFile(String smbPath, {int size = 1, int attributes = 0x20}) {
   return SmbFile(
     smbPath,
     _uncPath(smbPath),
@@ -123,7 +198,7 @@ SmbFile smbFile(String smbPath, {int size = 1}) {
     0,
     0,
     0,
-    0x20,
+    attributes,
     size,
     true,
   );
@@ -0,0 +1,16 @@
+import 'package:flutter_test/flutter_test.dart';
+import 'package:open_filmly/core/formatters/title_normalizer.dart';
+
+void main() {
+  test('folds full-width characters and traditional Chinese', () {
+    expect(normalizeTitle('權力的遊戲　第八季'), '权力的游戏 第八季');
+    expect(normalizeTitle('ＧＯＴ：Ｓ０８'), 'GOT:S08');
+    expect(normalizeTitle('Amélie'), 'Amélie');
+  });
+
+  test('match keys also lowercase and drop diacritics', () {
+    expect(titleMatchKey('Amélie'), 'amelie');
+    expect(titleMatchKey('Die Straße'), 'die strasse');
+    expect(titleMatchKey('龍貓'), titleMatchKey('龙猫'));
+  });
+}
@@ -288,4 +288,73 @@ void main() {
     // And the tmdb id is now stored for episode-level lookups.
     expect(updated.tmdbId, 11);
   });
+
+  test('serves repeated lookups from the response cache', () async {
+    const dune = Media(
+      id: '/Movies/Dune.2021.mkv',
+      title: 'Dune',
+      year: '2021',
+      type: MediaType.movie,
+      path: '/Movies/Dune.2021.mkv',
+    );
+    var requests = 0;
+    handler = (request) async {
+      requests++;
+      request.response
+        ..statusCode = 200
+        ..write(jsonEncode({'id': 42, 'title': '沙丘'}));
+      await request.response.close();
+    };
+
+    final first = await tmdb.fetchDetails(
+      dune,
+      42,
+      MediaType.movie,
+      'demo-key',
+    );
+    final second = await tmdb.fetchDetails(
+      dune,
+      42,
+      MediaType.movie,
+      'demo-key',
+    );
+
+    expect(first, isNotNull);
+    expect(second, isNotNull);
+    expect(requests, 1);
+  });
+
+  test('waits out a 429 and retries', () async {
+    const dune = Media(
+      id: '/Movies/Dune.2021.mkv',
+      title: 'Dune',
+      year: '2021',
+      type: MediaType.movie,
+      path: '/Movies/Dune.2021.mkv',
+    );
+    var requests = 0;
+    handler = (request) async {
+      requests++;
+      if (requests == 1) {
+        request.response
+          ..statusCode = 429
+          ..headers.set('Retry-After', '0');
+      } else {
+        request.response
+          ..statusCode = 200
+          ..write(jsonEncode({'id': 42, 'title': '沙丘'}));
+      }
+      await request.response.close();
+    };
+
+    final details = await tmdb.fetchDetails(
+      dune,
+      42,
+      MediaType.movie,
+      'demo-key',
+    );
+
+    expect(details, isNotNull);
+    expect(requests, 2);
+  });
 }